@@ -0,0 +1,183 @@
+package binding
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"letgo/context"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Bind 将请求数据绑定到ptr指向的结构体：
+// 先按Content-Type解析请求体（json、xml、url编码表单、multipart表单），
+// 再按字段的tag依次从query、form、path、header、cookie中取值，后者覆盖前者。
+// 未指定tag的结构体字段会递归绑定。
+func Bind(ctx context.Context, ptr interface{}) error {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return genError(fmt.Sprintf("bind target must be a pointer to struct, got %T", ptr))
+	}
+
+	req := ctx.Request()
+	if err := bindBody(ctx.Response(), req, ptr); err != nil {
+		return err
+	}
+
+	s := &sources{ctx: ctx, query: req.URL.Query()}
+	return bindStruct(v.Elem(), s)
+}
+
+// bindBody 按Content-Type解析请求体，未指定Content-Type时按json解析。
+// 除multipart表单外，请求体超过MaxBodySize时返回BodyTooLargeError。
+func bindBody(rw http.ResponseWriter, req *http.Request, ptr interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	switch mediaType {
+	case MIME_Form:
+		if req.Body != nil {
+			req.Body = http.MaxBytesReader(rw, req.Body, MaxBodySize)
+		}
+		return bodyError(req.ParseForm())
+	case MIME_Multipart:
+		return req.ParseMultipartForm(MaxMultipartMemory)
+	}
+
+	if req.Body == nil {
+		return nil
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(rw, req.Body, MaxBodySize))
+	req.Body.Close()
+	if err != nil {
+		return bodyError(err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+
+	switch mediaType {
+	case MIME_XML, MIME_TextXML:
+		err = xml.Unmarshal(body, ptr)
+	default:
+		err = json.Unmarshal(body, ptr)
+	}
+	if err != nil {
+		return genError(fmt.Sprintf("decode body: %v", err))
+	}
+	return nil
+}
+
+// bodyError 读取请求体出错时的错误，超过MaxBodySize时为BodyTooLargeError。
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &BodyTooLargeError{Limit: tooLarge.Limit}
+	}
+	return err
+}
+
+var tags = []string{Tag_Query, Tag_Form, Tag_Path, Tag_Header, Tag_Cookie}
+
+func bindStruct(v reflect.Value, s *sources) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 && !sf.Anonymous {
+			continue
+		}
+		fv := v.Field(i)
+
+		tagged := false
+		for _, tag := range tags {
+			key, ok := sf.Tag.Lookup(tag)
+			if !ok || key == "-" {
+				continue
+			}
+			tagged = true
+			if key = strings.Split(key, ",")[0]; len(key) == 0 {
+				key = sf.Name
+			}
+
+			if tag == Tag_Form && isFileType(sf.Type) {
+				s.setFiles(fv, key)
+				continue
+			}
+			vals, ok := s.lookup(tag, key)
+			if !ok {
+				continue
+			}
+			if err := setValues(fv, vals, sf.Tag.Get(Tag_TimeFormat)); err != nil {
+				return &FieldError{Field: sf.Name, Source: tag, Key: key, Err: err}
+			}
+		}
+
+		if !tagged && fv.Kind() == reflect.Struct && !isScalarStruct(fv.Type()) {
+			if err := bindStruct(fv, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// sources 请求中各个来源的数据。
+type sources struct {
+	ctx   context.Context
+	query url.Values
+}
+
+func (s *sources) lookup(tag, key string) ([]string, bool) {
+	req := s.ctx.Request()
+	switch tag {
+	case Tag_Query:
+		vals, ok := s.query[key]
+		return vals, ok
+	case Tag_Form:
+		vals, ok := req.PostForm[key]
+		return vals, ok
+	case Tag_Path:
+		for _, p := range s.ctx.Params() {
+			if p.Key == key {
+				return []string{p.Value}, true
+			}
+		}
+	case Tag_Header:
+		vals, ok := req.Header[textproto.CanonicalMIMEHeaderKey(key)]
+		return vals, ok
+	case Tag_Cookie:
+		if c, err := req.Cookie(key); err == nil {
+			return []string{c.Value}, true
+		}
+	}
+	return nil, false
+}
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+)
+
+func isFileType(t reflect.Type) bool {
+	return t == fileHeaderType || t == fileHeadersType
+}
+
+// setFiles 绑定multipart表单中的文件。
+func (s *sources) setFiles(fv reflect.Value, key string) {
+	form := s.ctx.Request().MultipartForm
+	if form == nil || len(form.File[key]) == 0 {
+		return
+	}
+	files := form.File[key]
+	if fv.Type() == fileHeaderType {
+		fv.Set(reflect.ValueOf(files[0]))
+	} else {
+		fv.Set(reflect.ValueOf(files))
+	}
+}
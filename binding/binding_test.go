@@ -0,0 +1,170 @@
+package binding
+
+import (
+	"bytes"
+	"errors"
+	"letgo/context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindPaging struct {
+	Sort string `query:"sort"`
+}
+
+type bindTarget struct {
+	Page    int       `query:"page"`
+	Tags    []string  `query:"tag"`
+	Limit   *int      `query:"limit"`
+	Since   time.Time `query:"since" time_format:"2006-01-02"`
+	ID      int64     `path:"id"`
+	Name    string    `form:"name" json:"name" xml:"name"`
+	Age     int       `form:"age" json:"age" xml:"age"`
+	Token   string    `header:"X-Token"`
+	Session string    `cookie:"sid"`
+	Paging  bindPaging
+}
+
+func newBindContext(req *http.Request, params context.Params) context.Context {
+	ctx := context.New()
+	ctx.Reset(httptest.NewRecorder(), req)
+	ctx.SetParams(params)
+	return ctx
+}
+
+func newMultipartBody(t *testing.T, fields map[string]string) (string, *bytes.Buffer) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return w.FormDataContentType(), &body
+}
+
+func TestBind(t *testing.T) {
+	limit := 20
+	multipartType, multipartBody := newMultipartBody(t, map[string]string{"name": "mp", "age": "4"})
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		params      context.Params
+		header      map[string]string
+		want        bindTarget
+	}{
+		{"query", "/?page=2&tag=a&tag=b&limit=20&since=2024-05-06&sort=name", "", "", nil, nil,
+			bindTarget{Page: 2, Tags: []string{"a", "b"}, Limit: &limit, Since: time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), Paging: bindPaging{Sort: "name"}}},
+		{"empty pointer", "/?limit=", "", "", nil, nil, bindTarget{}},
+		{"path", "/", "", "", context.Params{{Key: "id", Value: "42"}}, nil, bindTarget{ID: 42}},
+		{"form", "/?page=1", MIME_Form, "name=go&age=3", nil, nil, bindTarget{Page: 1, Name: "go", Age: 3}},
+		{"multipart", "/", multipartType, multipartBody.String(), nil, nil, bindTarget{Name: "mp", Age: 4}},
+		{"json", "/", MIME_JSON + "; charset=utf-8", `{"name":"js","age":5}`, nil, nil, bindTarget{Name: "js", Age: 5}},
+		{"json by default", "/", "", `{"name":"js"}`, nil, nil, bindTarget{Name: "js"}},
+		{"xml", "/", MIME_XML, `<target><name>x</name><age>6</age></target>`, nil, nil, bindTarget{Name: "x", Age: 6}},
+		{"text xml", "/", MIME_TextXML, `<target><name>x</name></target>`, nil, nil, bindTarget{Name: "x"}},
+		{"header and cookie", "/", "", "", nil, map[string]string{"X-Token": "t", "Cookie": "sid=s1"}, bindTarget{Token: "t", Session: "s1"}},
+		// 解析请求体后再按tag绑定其它来源
+		{"json and query", "/?page=3", MIME_JSON, `{"name":"js","age":5}`, nil, nil, bindTarget{Page: 3, Name: "js", Age: 5}},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+		if len(tt.contentType) > 0 {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		var got bindTarget
+		if err := Bind(newBindContext(req, tt.params), &got); err != nil {
+			t.Errorf("%s: Bind: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Bind = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		params      context.Params
+		source      string // 为空时不是FieldError
+		key         string
+	}{
+		{"query int", "/?page=x", "", "", nil, Tag_Query, "page"},
+		{"query pointer", "/?limit=1.5", "", "", nil, Tag_Query, "limit"},
+		{"query time", "/?since=06/05/2024", "", "", nil, Tag_Query, "since"},
+		{"path int", "/", "", "", context.Params{{Key: "id", Value: "abc"}}, Tag_Path, "id"},
+		{"form int", "/", MIME_Form, "age=old", nil, Tag_Form, "age"},
+		{"json", "/", MIME_JSON, `{"age":"old"}`, nil, "", ""},
+		{"xml", "/", MIME_XML, `<target><age>`, nil, "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+		if len(tt.contentType) > 0 {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		var got bindTarget
+		err := Bind(newBindContext(req, tt.params), &got)
+		if err == nil {
+			t.Errorf("%s: Bind: want error", tt.name)
+			continue
+		}
+		var fe *FieldError
+		if isField := errors.As(err, &fe); isField != (len(tt.source) > 0) {
+			t.Errorf("%s: Bind: %v, FieldError %v", tt.name, err, isField)
+			continue
+		}
+		if fe != nil && (fe.Source != tt.source || fe.Key != tt.key) {
+			t.Errorf("%s: FieldError source %s key %s, want %s %s", tt.name, fe.Source, fe.Key, tt.source, tt.key)
+		}
+	}
+
+	var v bindTarget
+	if err := Bind(newBindContext(httptest.NewRequest(http.MethodGet, "/", nil), nil), v); err == nil {
+		t.Error("Bind non-pointer: want error")
+	}
+}
+
+func TestBindMaxBodySize(t *testing.T) {
+	defer func(size int64) { MaxBodySize = size }(MaxBodySize)
+	MaxBodySize = 16
+
+	for _, contentType := range []string{MIME_JSON, MIME_XML, MIME_Form} {
+		body := `{"name":"` + strings.Repeat("a", 32) + `"}`
+		if contentType == MIME_Form {
+			body = "name=" + strings.Repeat("a", 32)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		var got bindTarget
+		err := Bind(newBindContext(req, nil), &got)
+		var tooLarge *BodyTooLargeError
+		if !errors.As(err, &tooLarge) || tooLarge.Limit != 16 || tooLarge.StatusCode() != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: Bind: %v, want BodyTooLargeError", contentType, err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"age":1}`))
+	var got bindTarget
+	if err := Bind(newBindContext(req, nil), &got); err != nil || got.Age != 1 {
+		t.Errorf("Bind within limit: %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,127 @@
+package binding
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// isScalarStruct 按单个值绑定的结构体类型，如time.Time。
+func isScalarStruct(t reflect.Type) bool {
+	return t == timeType || reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// setValues 将字符串值转换后设置到字段，切片字段使用全部的值，其它字段使用第一个值。
+func setValues(fv reflect.Value, vals []string, timeFormat string) error {
+	if len(vals) == 0 {
+		return nil
+	}
+	if fv.Kind() == reflect.Slice && !reflect.PtrTo(fv.Type()).Implements(textUnmarshalerType) {
+		slice := reflect.MakeSlice(fv.Type(), len(vals), len(vals))
+		for i, val := range vals {
+			if err := setValue(slice.Index(i), val, timeFormat); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setValue(fv, vals[0], timeFormat)
+}
+
+// setValue 将字符串转换为字段的类型，支持基本类型、指针、time.Time、time.Duration
+// 和实现了encoding.TextUnmarshaler的类型。空字符串保留字段的零值。
+func setValue(fv reflect.Value, val string, timeFormat string) error {
+	if fv.Kind() == reflect.Ptr {
+		if len(val) == 0 {
+			return nil
+		}
+		elem := reflect.New(fv.Type().Elem())
+		if err := setValue(elem.Elem(), val, timeFormat); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+
+	switch fv.Type() {
+	case timeType:
+		if len(val) == 0 {
+			return nil
+		}
+		if len(timeFormat) == 0 {
+			timeFormat = time.RFC3339
+		}
+		t, err := time.Parse(timeFormat, val)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		if len(val) == 0 {
+			return nil
+		}
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	if fv.CanAddr() {
+		if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(val))
+		}
+	}
+
+	if fv.Kind() == reflect.String {
+		fv.SetString(val)
+		return nil
+	}
+	if len(val) == 0 {
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.Bool:
+		if val == "on" {
+			val = "true"
+		}
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(val, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(val, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package binding
+
+// 结构体字段的tag，指定数据的来源及名称，如 `query:"page"`。
+const (
+	Tag_Query  = "query"
+	Tag_Form   = "form"
+	Tag_Path   = "path"
+	Tag_Header = "header"
+	Tag_Cookie = "cookie"
+
+	// Tag_TimeFormat 时间字段的格式，默认为RFC3339。
+	Tag_TimeFormat = "time_format"
+)
+
+const (
+	MIME_JSON      = "application/json"
+	MIME_XML       = "application/xml"
+	MIME_TextXML   = "text/xml"
+	MIME_Form      = "application/x-www-form-urlencoded"
+	MIME_Multipart = "multipart/form-data"
+)
+
+// MaxMultipartMemory 解析multipart表单时使用的最大内存，超出的部分写入临时文件。
+var MaxMultipartMemory int64 = 32 << 20
+
+// MaxBodySize 解析json、xml和url编码表单时请求体的最大字节数。
+var MaxBodySize int64 = 10 << 20
@@ -0,0 +1,43 @@
+package binding
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var errorPrefix = "binding error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
+
+// FieldError 绑定字段时的错误。
+type FieldError struct {
+	Field  string // 结构体字段名
+	Source string // 数据来源，如 query、path
+	Key    string // 数据来源中的名称
+	Err    error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: bind %s %q to field %s: %v", errorPrefix, e.Source, e.Key, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BodyTooLargeError 请求体超过MaxBodySize。
+type BodyTooLargeError struct {
+	Limit int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("%s: request body exceeds %d bytes", errorPrefix, e.Limit)
+}
+
+// StatusCode 错误对应的HTTP状态码。
+func (e *BodyTooLargeError) StatusCode() int {
+	return http.StatusRequestEntityTooLarge
+}
@@ -1,16 +1,46 @@
 package context
 
-import "net/http"
+import (
+	stdctx "context"
+	"net/http"
+	"time"
+)
 
+// Context 请求的上下文，实现了标准库的context.Context：
+// 截止时间和取消信号来自请求的Context，客户端断开连接时Done关闭，
+// 可以直接传给数据库等下游调用。Context在请求结束后会被复用，不能在请求之外保留，
+// 需要在后台使用时传递Request().Context()。
 type Context interface {
+	stdctx.Context
+
 	Request() *http.Request
-	Response() http.ResponseWriter
+	// Response 包装后的ResponseWriter，可以获取状态码、写入的字节数等。
+	Response() ResponseWriter
 	Reset(rw http.ResponseWriter, req *http.Request)
+	// SetRequest 替换请求，如使用WithContext设置超时后的请求，不清空路径参数和存储的值。
+	SetRequest(req *http.Request)
+	// SetResponse 替换ResponseWriter，不是ResponseWriter时包装后使用，不清空路径参数和存储的值。
+	SetResponse(rw http.ResponseWriter)
+
+	// Param 获取路由捕获的路径参数，如 /api/user/:id 中的 id。
+	Param(key string) string
+	Params() Params
+	SetParams(params Params)
+
+	// Set 保存请求范围内的值，如中间件解析出的用户、请求ID。
+	Set(key string, value interface{})
+	// Get 获取Set保存的值。
+	Get(key string) (value interface{}, ok bool)
+	// GetString 获取Set保存的字符串，不存在或不是字符串时返回空字符串。
+	GetString(key string) string
 }
 
 type myContext struct {
 	request        *http.Request
-	responseWriter http.ResponseWriter
+	responseWriter ResponseWriter
+	writer         responseWriter // 复用的ResponseWriter
+	params         Params
+	values         map[string]interface{}
 }
 
 func New() Context {
@@ -21,11 +51,75 @@ func (ctx *myContext) Request() *http.Request {
 	return ctx.request
 }
 
-func (ctx *myContext) Response() http.ResponseWriter {
+func (ctx *myContext) Response() ResponseWriter {
 	return ctx.responseWriter
 }
 
 func (ctx *myContext) Reset(rw http.ResponseWriter, req *http.Request) {
-	ctx.responseWriter = rw
+	ctx.writer.reset(rw)
+	ctx.responseWriter = &ctx.writer
 	ctx.request = req
+	ctx.params = ctx.params[:0]
+	for key := range ctx.values {
+		delete(ctx.values, key)
+	}
+}
+
+func (ctx *myContext) SetRequest(req *http.Request) {
+	ctx.request = req
+}
+
+func (ctx *myContext) SetResponse(rw http.ResponseWriter) {
+	ctx.responseWriter = NewResponseWriter(rw)
+}
+
+func (ctx *myContext) Param(key string) string {
+	return ctx.params.Get(key)
+}
+
+func (ctx *myContext) Params() Params {
+	return ctx.params
+}
+
+func (ctx *myContext) SetParams(params Params) {
+	ctx.params = append(ctx.params[:0], params...)
+}
+
+func (ctx *myContext) Set(key string, value interface{}) {
+	if ctx.values == nil {
+		ctx.values = make(map[string]interface{})
+	}
+	ctx.values[key] = value
+}
+
+func (ctx *myContext) Get(key string) (value interface{}, ok bool) {
+	value, ok = ctx.values[key]
+	return
+}
+
+func (ctx *myContext) GetString(key string) string {
+	s, _ := ctx.values[key].(string)
+	return s
+}
+
+func (ctx *myContext) Deadline() (deadline time.Time, ok bool) {
+	return ctx.request.Context().Deadline()
+}
+
+func (ctx *myContext) Done() <-chan struct{} {
+	return ctx.request.Context().Done()
+}
+
+func (ctx *myContext) Err() error {
+	return ctx.request.Context().Err()
+}
+
+// Value 字符串key优先查找Set保存的值，其它的查找请求的Context。
+func (ctx *myContext) Value(key interface{}) interface{} {
+	if k, ok := key.(string); ok {
+		if value, ok := ctx.values[k]; ok {
+			return value
+		}
+	}
+	return ctx.request.Context().Value(key)
 }
@@ -0,0 +1,88 @@
+package context
+
+import (
+	stdctx "context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+func TestValues(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req = req.WithContext(stdctx.WithValue(req.Context(), ctxKey{}, "from request"))
+	ctx := New()
+	ctx.Reset(httptest.NewRecorder(), req)
+
+	ctx.Set("user", "alice")
+	ctx.Set("id", 7)
+	if v, ok := ctx.Get("user"); !ok || v != "alice" {
+		t.Errorf("Get(user) = %v, %v", v, ok)
+	}
+	if _, ok := ctx.Get("missing"); ok {
+		t.Error("Get(missing): want not found")
+	}
+	if ctx.GetString("user") != "alice" || ctx.GetString("id") != "" {
+		t.Errorf("GetString: %q %q", ctx.GetString("user"), ctx.GetString("id"))
+	}
+
+	// 字符串key优先查找Set保存的值，其它key查找请求的Context
+	if ctx.Value("user") != "alice" || ctx.Value(ctxKey{}) != "from request" || ctx.Value("missing") != nil {
+		t.Errorf("Value: %v %v", ctx.Value("user"), ctx.Value(ctxKey{}))
+	}
+
+	// 替换请求时保留路径参数和保存的值
+	ctx.SetParams(Params{{Key: "id", Value: "1"}})
+	ctx.SetRequest(httptest.NewRequest(http.MethodPost, "/x", nil))
+	if ctx.Param("id") != "1" || ctx.GetString("user") != "alice" || ctx.Request().Method != http.MethodPost {
+		t.Error("SetRequest cleared params or values")
+	}
+	ctx.SetResponse(httptest.NewRecorder())
+	if ctx.Param("id") != "1" || ctx.Response().Written() {
+		t.Error("SetResponse cleared params or wrapped a written response")
+	}
+
+	// 复用时清空上一个请求的数据
+	ctx.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if _, ok := ctx.Get("user"); ok || len(ctx.Params()) != 0 || ctx.Value(ctxKey{}) != nil {
+		t.Error("Reset kept values of the previous request")
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	parent, cancel := stdctx.WithCancel(stdctx.Background())
+	deadline := time.Now().Add(time.Hour)
+	parent, cancelTimeout := stdctx.WithDeadline(parent, deadline)
+	defer cancelTimeout()
+
+	ctx := New()
+	ctx.Reset(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil).WithContext(parent))
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+		t.Errorf("Deadline = %v, %v, want %v", d, ok, deadline)
+	}
+
+	// 可以作为标准库的Context传给下游调用
+	child, cancelChild := stdctx.WithTimeout(ctx, time.Minute)
+	defer cancelChild()
+	if ctx.Err() != nil {
+		t.Fatalf("Err before cancel: %v", ctx.Err())
+	}
+
+	cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Done not closed after the request was canceled")
+	}
+	select {
+	case <-child.Done():
+	case <-time.After(time.Second):
+		t.Fatal("derived context not canceled")
+	}
+	if !errors.Is(ctx.Err(), stdctx.Canceled) || !errors.Is(child.Err(), stdctx.Canceled) {
+		t.Errorf("Err = %v, child %v, want context.Canceled", ctx.Err(), child.Err())
+	}
+}
@@ -0,0 +1,20 @@
+package context
+
+// Param 路由中捕获的路径参数。
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params 按路由格式中出现顺序排列的路径参数。
+type Params []Param
+
+// Get 获取指定名称的参数值，不存在时返回空字符串。
+func (ps Params) Get(key string) string {
+	for _, p := range ps {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
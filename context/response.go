@@ -0,0 +1,140 @@
+package context
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ResponseWriter 包装http.ResponseWriter，记录状态码、写入的字节数和耗时，
+// 状态码只写入一次，重复调用WriteHeader会被忽略，1xx信息响应除外。
+type ResponseWriter interface {
+	http.ResponseWriter
+	http.Flusher
+	http.Hijacker
+	http.Pusher
+
+	// Status 写入的状态码，只调用Write时为200，未写入时为0。
+	Status() int
+	// Size 写入的响应体字节数。
+	Size() int64
+	// Written 是否已写入响应头。
+	Written() bool
+	// Duration 从开始处理请求到现在的耗时。
+	Duration() time.Duration
+	// Before 添加写入响应头前调用的函数，按添加的顺序调用，可以修改响应头。
+	Before(fn func(rw ResponseWriter))
+	// Unwrap 被包装的ResponseWriter，用于http.ResponseController。
+	Unwrap() http.ResponseWriter
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+	start  time.Time
+	before []func(rw ResponseWriter)
+}
+
+// NewResponseWriter 包装http.ResponseWriter，已经是ResponseWriter时直接返回。
+func NewResponseWriter(rw http.ResponseWriter) ResponseWriter {
+	if w, ok := rw.(ResponseWriter); ok {
+		return w
+	}
+	w := &responseWriter{}
+	w.reset(rw)
+	return w
+}
+
+func (w *responseWriter) reset(rw http.ResponseWriter) {
+	w.ResponseWriter = rw
+	w.status = 0
+	w.size = 0
+	w.start = time.Now()
+	w.before = w.before[:0]
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.Written() {
+		return
+	}
+	// 1xx信息响应（如103 Early Hints）可以发送多次，不是最终的状态码
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	// 先设置状态码，避免在Before中写入时重复调用
+	w.status = code
+	for _, fn := range w.before {
+		fn(w)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Status() int {
+	return w.status
+}
+
+func (w *responseWriter) Size() int64 {
+	return w.size
+}
+
+func (w *responseWriter) Written() bool {
+	return w.status != 0
+}
+
+func (w *responseWriter) Duration() time.Duration {
+	return time.Since(w.start)
+}
+
+func (w *responseWriter) Before(fn func(rw ResponseWriter)) {
+	w.before = append(w.before, fn)
+}
+
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *responseWriter) Flush() {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if !ok {
+		return
+	}
+	if !w.Written() {
+		w.WriteHeader(http.StatusOK)
+	}
+	flusher.Flush()
+}
+
+// Hijack 接管连接，被包装的ResponseWriter不支持时返回http.ErrNotSupported。
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err == nil && !w.Written() {
+		// 连接被接管后由调用方写入响应
+		w.status = http.StatusSwitchingProtocols
+	}
+	return conn, buf, err
+}
+
+// Push HTTP/2服务端推送，被包装的ResponseWriter不支持时返回http.ErrNotSupported。
+func (w *responseWriter) Push(target string, opts *http.PushOptions) error {
+	pusher, ok := w.ResponseWriter.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+	return pusher.Push(target, opts)
+}
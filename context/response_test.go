@@ -0,0 +1,48 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// statusRecorder 记录每次调用WriteHeader的状态码。
+type statusRecorder struct {
+	*httptest.ResponseRecorder
+	codes []int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.codes = append(r.codes, code)
+	if code >= 200 {
+		r.ResponseRecorder.WriteHeader(code)
+	}
+}
+
+func TestWriteHeaderInformational(t *testing.T) {
+	rec := &statusRecorder{ResponseRecorder: httptest.NewRecorder()}
+	w := NewResponseWriter(rec)
+	before := 0
+	w.Before(func(rw ResponseWriter) { before++ })
+
+	w.WriteHeader(http.StatusEarlyHints)
+	w.WriteHeader(http.StatusEarlyHints)
+	if w.Written() || w.Status() != 0 || before != 0 {
+		t.Errorf("after 103: written %v, status %d, before called %d times", w.Written(), w.Status(), before)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	w.WriteHeader(http.StatusOK)
+	if w.Status() != http.StatusCreated || before != 1 {
+		t.Errorf("after 201: status %d, before called %d times", w.Status(), before)
+	}
+	want := []int{http.StatusEarlyHints, http.StatusEarlyHints, http.StatusCreated}
+	if len(rec.codes) != len(want) {
+		t.Fatalf("codes %v, want %v", rec.codes, want)
+	}
+	for i := range want {
+		if rec.codes[i] != want[i] {
+			t.Errorf("codes %v, want %v", rec.codes, want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"letgo/binding"
+	"letgo/context"
+	"letgo/render"
+	"letgo/view"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+// JSONPCallback ServeJSONP使用的回调函数名的查询参数。
+var JSONPCallback = "callback"
+
+var jsonpCallback = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$.]*$`)
+
+// Base 控制器的默认实现，应用的控制器嵌入Base即可实现Controller接口：
+//
+//	type AccountController struct {
+//		controller.Base
+//	}
+//
+// 自动路由不会将Base的方法注册为路由。
+type Base struct {
+	ctx    context.Context
+	status int    // 待写入的状态码
+	body   []byte // 已读取的请求体
+	read   bool
+	view   view.View
+}
+
+func (c *Base) Init(ctx context.Context) {
+	c.ctx = ctx
+	c.status = 0
+	c.body = nil
+	c.read = false
+}
+
+// Ctx 当前请求的上下文。
+func (c *Base) Ctx() context.Context {
+	return c.ctx
+}
+
+func (c *Base) Request() *http.Request {
+	return c.ctx.Request()
+}
+
+func (c *Base) Response() context.ResponseWriter {
+	return c.ctx.Response()
+}
+
+// SetStatus 设置响应的状态码，在输出响应内容时写入。
+func (c *Base) SetStatus(code int) {
+	c.status = code
+}
+
+// WriteStatus 写入响应头和状态码，未设置状态码时为200。
+// 使用Response()直接输出内容前调用。
+func (c *Base) WriteStatus() {
+	if c.status == 0 {
+		return
+	}
+	c.ctx.Response().WriteHeader(c.status)
+	c.status = 0
+}
+
+// Header 获取请求头。
+func (c *Base) Header(key string) string {
+	return c.ctx.Request().Header.Get(key)
+}
+
+// SetHeader 设置响应头。
+func (c *Base) SetHeader(key, value string) {
+	c.ctx.Response().Header().Set(key, value)
+}
+
+// Cookie 获取请求中的cookie值，不存在时返回空字符串。
+func (c *Base) Cookie(name string) string {
+	cookie, err := c.ctx.Request().Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+// SetCookie 设置响应的cookie。
+func (c *Base) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.ctx.Response(), cookie)
+}
+
+// Redirect 重定向到url，code为3xx状态码，为0时使用302。
+func (c *Base) Redirect(url string, code int) {
+	if code == 0 {
+		code = http.StatusFound
+	}
+	http.Redirect(c.ctx.Response(), c.ctx.Request(), url, code)
+}
+
+// Serve 按Accept请求头选择编码器输出数据，默认json，见render.Negotiate。
+// 编码失败时panic，由路由输出500错误。
+func (c *Base) Serve(data interface{}) {
+	enc := render.Negotiate(c.Header("Accept"))
+	body, err := enc.Marshal(data)
+	if err != nil {
+		// 选择的编码器无法序列化数据时（如xml不支持map）使用json
+		enc = render.Default()
+		body, err = enc.Marshal(data)
+	}
+	if err != nil {
+		panic(err)
+	}
+	c.ServeBlob(enc.Type(), body)
+}
+
+func (c *Base) ServeJSON(data interface{}) {
+	c.serveType(render.MIME_JSON, data)
+}
+
+func (c *Base) ServeXML(data interface{}) {
+	c.serveType(render.MIME_XML, data)
+}
+
+func (c *Base) ServeYAML(data interface{}) {
+	c.serveType(render.MIME_YAML, data)
+}
+
+func (c *Base) ServeMsgPack(data interface{}) {
+	c.serveType(render.MIME_MsgPack, data)
+}
+
+// ServeJSONP 输出JSONP，回调函数名为查询参数JSONPCallback的值，
+// 回调函数名为空或不合法时按JSON输出。
+func (c *Base) ServeJSONP(data interface{}) {
+	callback := c.Query(JSONPCallback)
+	if !jsonpCallback.MatchString(callback) {
+		c.ServeJSON(data)
+		return
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		panic(err)
+	}
+	c.SetHeader("X-Content-Type-Options", "nosniff")
+	c.ServeBlob(render.MIME_JSONP+render.Charset_UTF8, []byte("/**/"+callback+"("+string(body)+");"))
+}
+
+// ServeText 输出纯文本。
+func (c *Base) ServeText(text string) {
+	c.ServeBlob(render.MIME_Text+render.Charset_UTF8, []byte(text))
+}
+
+// ServeHTML 输出HTML，内容不做转义。
+func (c *Base) ServeHTML(html string) {
+	c.ServeBlob(render.MIME_HTML+render.Charset_UTF8, []byte(html))
+}
+
+// ServeBlob 以指定的Content-Type输出内容。
+func (c *Base) ServeBlob(contentType string, body []byte) {
+	c.SetHeader("Content-Type", contentType)
+	c.WriteStatus()
+	c.ctx.Response().Write(body)
+}
+
+func (c *Base) serveType(mediaType string, data interface{}) {
+	enc, ok := render.Lookup(mediaType)
+	if !ok {
+		panic(genError(fmt.Sprintf("encoder %s not registed", mediaType)))
+	}
+	c.serveEncoded(enc, data)
+}
+
+func (c *Base) serveEncoded(enc render.Encoder, data interface{}) {
+	body, err := enc.Marshal(data)
+	if err != nil {
+		panic(err)
+	}
+	c.ServeBlob(enc.Type(), body)
+}
+
+func (c *Base) SetView(v view.View) {
+	c.view = v
+}
+
+// Render 使用默认布局渲染模板并输出HTML，模板名见view.View。
+// 渲染失败时不写入响应，可以作为方法的返回值，由路由输出错误：
+//
+//	func (c *UserController) Profile() error {
+//		return c.Render("user/profile", data)
+//	}
+func (c *Base) Render(name string, data interface{}) error {
+	if c.view == nil {
+		return genError("view not set")
+	}
+	var buf bytes.Buffer
+	if err := c.view.Render(&buf, name, data); err != nil {
+		return err
+	}
+	c.ServeHTML(buf.String())
+	return nil
+}
+
+// RenderLayout 使用指定的布局渲染模板，layout为空时不使用布局。
+func (c *Base) RenderLayout(layout, name string, data interface{}) error {
+	if c.view == nil {
+		return genError("view not set")
+	}
+	var buf bytes.Buffer
+	if err := c.view.RenderLayout(&buf, layout, name, data); err != nil {
+		return err
+	}
+	c.ServeHTML(buf.String())
+	return nil
+}
+
+// Param 获取路由中的路径参数。
+func (c *Base) Param(key string) string {
+	return c.ctx.Param(key)
+}
+
+func (c *Base) Query(key string) string {
+	req := c.ctx.Request()
+	if req.Form == nil {
+		req.ParseForm()
+	}
+	return req.Form.Get(key)
+}
+
+// QueryStrings 获取查询参数或表单中同名的全部值。
+func (c *Base) QueryStrings(key string) []string {
+	req := c.ctx.Request()
+	if req.Form == nil {
+		req.ParseForm()
+	}
+	return req.Form[key]
+}
+
+// QueryInt 获取int类型的查询参数，不存在或格式错误时返回def。
+func (c *Base) QueryInt(key string, def int) int {
+	v, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// QueryInt64 获取int64类型的查询参数，不存在或格式错误时返回def。
+func (c *Base) QueryInt64(key string, def int64) int64 {
+	v, err := strconv.ParseInt(c.Query(key), 10, 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// QueryBool 获取bool类型的查询参数，不存在或格式错误时返回def。
+func (c *Base) QueryBool(key string, def bool) bool {
+	v, err := strconv.ParseBool(c.Query(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// QueryFloat64 获取float64类型的查询参数，不存在或格式错误时返回def。
+func (c *Base) QueryFloat64(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(c.Query(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// Body 读取请求体，读取后请求体可以再次读取。
+func (c *Base) Body() ([]byte, error) {
+	if c.read {
+		return c.body, nil
+	}
+	req := c.ctx.Request()
+	if req.Body == nil {
+		c.read = true
+		return nil, nil
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	c.body, c.read = body, true
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// Bind 将请求数据绑定到ptr指向的结构体，见binding.Bind。
+func (c *Base) Bind(ptr interface{}) error {
+	if c.read {
+		c.ctx.Request().Body = ioutil.NopCloser(bytes.NewReader(c.body))
+	}
+	return binding.Bind(c.ctx, ptr)
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"io"
+	"io/ioutil"
+	"letgo/context"
+	"letgo/view"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBase 创建处理请求的Base，返回记录响应的ResponseRecorder。
+func newBase(method, target string, body io.Reader) (*Base, *httptest.ResponseRecorder) {
+	rw := httptest.NewRecorder()
+	ctx := context.New()
+	ctx.Reset(rw, httptest.NewRequest(method, target, body))
+	c := &Base{}
+	c.Init(ctx)
+	return c, rw
+}
+
+type item struct {
+	A int
+}
+
+func TestServe(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		serve       func(c *Base)
+		status      int
+		contentType string
+		body        string
+	}{
+		{"text", "", func(c *Base) { c.ServeText("hi") }, http.StatusOK, "text/plain; charset=utf-8", "hi"},
+		{"status", "", func(c *Base) { c.SetStatus(http.StatusCreated); c.ServeHTML("<b>") }, http.StatusCreated, "text/html; charset=utf-8", "<b>"},
+		{"json", "", func(c *Base) { c.ServeJSON(map[string]int{"a": 1}) }, http.StatusOK, "application/json; charset=utf-8", `{"a":1}`},
+		{"xml", "", func(c *Base) { c.ServeXML(item{1}) }, http.StatusOK, "application/xml; charset=utf-8", "<item><A>1</A></item>"},
+		{"yaml", "", func(c *Base) { c.ServeYAML(map[string]int{"a": 1}) }, http.StatusOK, "application/yaml; charset=utf-8", "a: 1\n"},
+		{"msgpack", "", func(c *Base) { c.ServeMsgPack(1) }, http.StatusOK, "application/msgpack", "\x01"},
+		{"negotiate xml", "application/xml", func(c *Base) { c.Serve(item{1}) }, http.StatusOK, "application/xml; charset=utf-8", "<item><A>1</A></item>"},
+		// xml不支持map时使用json
+		{"negotiate fallback", "application/xml", func(c *Base) { c.Serve(map[string]int{"a": 1}) }, http.StatusOK, "application/json; charset=utf-8", `{"a":1}`},
+		{"blob", "", func(c *Base) { c.ServeBlob("image/png", []byte("png")) }, http.StatusOK, "image/png", "png"},
+	}
+	for _, tt := range tests {
+		c, rw := newBase(http.MethodGet, "/", nil)
+		if len(tt.accept) > 0 {
+			c.Request().Header.Set("Accept", tt.accept)
+		}
+		tt.serve(c)
+		if rw.Code != tt.status || rw.Header().Get("Content-Type") != tt.contentType || !strings.Contains(rw.Body.String(), tt.body) {
+			t.Errorf("%s: %d %s %q, want %d %s %q", tt.name, rw.Code, rw.Header().Get("Content-Type"), rw.Body, tt.status, tt.contentType, tt.body)
+		}
+	}
+}
+
+func TestServeJSONP(t *testing.T) {
+	tests := []struct {
+		target string
+		body   string
+	}{
+		{"/?callback=cb.done", `/**/cb.done({"a":1});`},
+		{"/?callback=alert(1)", `{"a":1}`},
+		{"/", `{"a":1}`},
+	}
+	for _, tt := range tests {
+		c, rw := newBase(http.MethodGet, tt.target, nil)
+		c.ServeJSONP(map[string]int{"a": 1})
+		if got := strings.TrimSpace(rw.Body.String()); got != tt.body {
+			t.Errorf("ServeJSONP %s = %s, want %s", tt.target, got, tt.body)
+		}
+	}
+}
+
+func TestRequestHelpers(t *testing.T) {
+	c, rw := newBase(http.MethodGet, "/?n=5&big=9000000000&ok=true&f=1.5&bad=x&tag=a&tag=b", nil)
+	c.Request().Header.Set("X-Id", "7")
+	c.Request().AddCookie(&http.Cookie{Name: "sid", Value: "s1"})
+	c.Ctx().SetParams(context.Params{{Key: "id", Value: "42"}})
+
+	if c.QueryInt("n", 0) != 5 || c.QueryInt("bad", -1) != -1 || c.QueryInt("missing", 3) != 3 {
+		t.Error("QueryInt")
+	}
+	if c.QueryInt64("big", 0) != 9000000000 || !c.QueryBool("ok", false) || c.QueryBool("bad", true) != true {
+		t.Error("QueryInt64 or QueryBool")
+	}
+	if c.QueryFloat64("f", 0) != 1.5 || c.QueryFloat64("bad", 2) != 2 {
+		t.Error("QueryFloat64")
+	}
+	if tags := c.QueryStrings("tag"); len(tags) != 2 || tags[1] != "b" {
+		t.Errorf("QueryStrings = %v", tags)
+	}
+	if c.Header("X-Id") != "7" || c.Cookie("sid") != "s1" || c.Cookie("missing") != "" || c.Param("id") != "42" {
+		t.Error("Header, Cookie or Param")
+	}
+
+	c.SetCookie(&http.Cookie{Name: "a", Value: "b"})
+	c.Redirect("/next", 0)
+	if rw.Code != http.StatusFound || rw.Header().Get("Location") != "/next" || !strings.HasPrefix(rw.Header().Get("Set-Cookie"), "a=b") {
+		t.Errorf("Redirect: %d %v", rw.Code, rw.Header())
+	}
+}
+
+func TestBodyAndBind(t *testing.T) {
+	c, _ := newBase(http.MethodPost, "/?page=2", strings.NewReader(`{"name":"go"}`))
+	c.Request().Header.Set("Content-Type", "application/json")
+
+	for i := 0; i < 2; i++ {
+		if body, err := c.Body(); err != nil || string(body) != `{"name":"go"}` {
+			t.Fatalf("Body #%d = %q, %v", i, body, err)
+		}
+	}
+	// Body读取后仍然可以绑定请求体
+	var in struct {
+		Name string `json:"name"`
+		Page int    `query:"page"`
+	}
+	if err := c.Bind(&in); err != nil || in.Name != "go" || in.Page != 2 {
+		t.Errorf("Bind after Body = %+v, %v", in, err)
+	}
+
+	// Init重置上一个请求的状态
+	c2, _ := newBase(http.MethodPost, "/", strings.NewReader("second"))
+	c.Init(c2.Ctx())
+	if body, _ := c.Body(); string(body) != "second" {
+		t.Errorf("Body after Init = %q", body)
+	}
+}
+
+func TestRender(t *testing.T) {
+	c, _ := newBase(http.MethodGet, "/", nil)
+	if err := c.Render("page", nil); err == nil {
+		t.Error("Render without view: want error")
+	}
+
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "layouts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	ioutil.WriteFile(filepath.Join(root, "page.html"), []byte("hello {{.}}"), 0644)
+	ioutil.WriteFile(filepath.Join(root, "layouts", "main.html"), []byte(`<main>{{template "content" .}}</main>`), 0644)
+	v := view.New(view.Options{Root: root, Layout: "main"})
+
+	c, rw := newBase(http.MethodGet, "/", nil)
+	c.SetView(v)
+	if err := c.Render("page", "<x>"); err != nil || rw.Body.String() != "<main>hello &lt;x&gt;</main>" {
+		t.Errorf("Render = %q, %v", rw.Body, err)
+	}
+	c, rw = newBase(http.MethodGet, "/", nil)
+	c.SetView(v)
+	if err := c.RenderLayout("", "page", "y"); err != nil || rw.Body.String() != "hello y" {
+		t.Errorf("RenderLayout without layout = %q, %v", rw.Body, err)
+	}
+	// 渲染失败时不写入响应
+	c, rw = newBase(http.MethodGet, "/", nil)
+	c.SetView(v)
+	if err := c.Render("missing", nil); err == nil || rw.Body.Len() > 0 || rw.Code != http.StatusOK {
+		t.Errorf("Render missing = %q, %v", rw.Body, err)
+	}
+}
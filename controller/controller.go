@@ -1,8 +1,8 @@
 package controller
 
 import (
-	"encoding/json"
 	"letgo/context"
+	"letgo/view"
 )
 
 type Controller interface {
@@ -11,26 +11,29 @@ type Controller interface {
 	Query(key string) string
 }
 
-type myController struct {
-	ctx context.Context
+// ActionLister 控制器实现ActionLister时，自动路由只注册Actions返回的方法。
+type ActionLister interface {
+	Actions() []string
 }
 
-func new() Controller {
-	return &myController{}
+// MethodExcluder 控制器实现MethodExcluder时，自动路由不注册ExcludeMethods返回的方法。
+type MethodExcluder interface {
+	ExcludeMethods() []string
 }
 
-func (c *myController) Init(ctx context.Context) {
-	c.ctx = ctx
+// Preparer 控制器实现Preparer时，在调用方法前执行Prepare，
+// 返回error时不调用方法，输出错误响应，如未登录时返回401。
+type Preparer interface {
+	Prepare() error
 }
 
-func (c *myController) ServeJSON(data interface{}) {
-	json.NewEncoder(c.ctx.Response()).Encode(data)
+// Finisher 控制器实现Finisher时，在请求处理完成后执行Finish，
+// Prepare返回error或方法panic时也会执行，用于释放资源、统计等。
+type Finisher interface {
+	Finish()
 }
 
-func (c *myController) Query(key string) string {
-	req := c.ctx.Request()
-	if req.Form == nil {
-		req.ParseForm()
-	}
-	return req.Form.Get(key)
+// ViewSetter 控制器实现ViewSetter时，路由在调用Init前设置渲染模板使用的View，见Base.Render。
+type ViewSetter interface {
+	SetView(v view.View)
 }
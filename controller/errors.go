@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errorPrefix = "controller error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
@@ -0,0 +1,22 @@
+package imaging
+
+// 缩放方式，见Variant.Mode。
+const (
+	Mode_Fit  = "fit"  // 保持比例缩放到宽高以内，不放大
+	Mode_Fill = "fill" // 保持比例缩放并居中裁剪到指定的宽高
+)
+
+// 图片格式，见Variant.Format。
+const (
+	Format_JPEG = "jpeg"
+	Format_PNG  = "png"
+	Format_GIF  = "gif"
+)
+
+// 默认配置。
+const (
+	Default_Quality = 85
+
+	// Max_Pixels 解码的最大像素数，避免解码超大的图片占用过多内存。
+	Max_Pixels = 40 << 20
+)
@@ -0,0 +1,15 @@
+package imaging
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errorPrefix = "imaging error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
+
+// ErrTooLarge 图片的像素数超过限制，见Decode。
+var ErrTooLarge = genError("image too large")
@@ -0,0 +1,200 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Variant 图片的变体，如缩略图。
+type Variant struct {
+	Name    string // 变体名，只能包含字母、数字、下划线和横线
+	Width   int    // 宽度，0时按高度保持比例
+	Height  int    // 高度，0时按宽度保持比例
+	Mode    string // 缩放方式，为空时使用Mode_Fit
+	Format  string // 输出格式，为空时与原图相同
+	Quality int    // JPEG的质量，0时使用Default_Quality
+}
+
+// Size 按变体的配置计算原图缩放后的宽高。
+func (v Variant) Size(width, height int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return 0, 0
+	}
+	// 宽或高为0时按另一边的比例缩放
+	sw, sh := float64(v.Width)/float64(width), float64(v.Height)/float64(height)
+	switch {
+	case v.Width <= 0 && v.Height <= 0:
+		return width, height
+	case v.Width <= 0:
+		sw = sh
+	case v.Height <= 0:
+		sh = sw
+	}
+	if v.Mode == Mode_Fill {
+		return scale(width, sw), scale(height, sh)
+	}
+
+	// 按较小的比例缩放，不放大
+	s := math.Min(math.Min(sw, sh), 1)
+	return scale(width, s), scale(height, s)
+}
+
+func scale(n int, s float64) int {
+	return max(1, int(math.Round(float64(n)*s)))
+}
+
+// Decode 解码PNG、JPEG、GIF图片，JPEG按EXIF中的方向旋转。GIF只解码第一帧。
+// 像素数超过maxPixels时返回ErrTooLarge，maxPixels为0时使用Max_Pixels。
+func Decode(r io.Reader, maxPixels int) (image.Image, string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, "", err
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if maxPixels == 0 {
+		maxPixels = Max_Pixels
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, "", ErrTooLarge
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if format == Format_JPEG {
+		img = orient(img, exifOrientation(data))
+	}
+	return img, format, nil
+}
+
+// Resize 按变体的配置缩放图片，Mode_Fill时先按目标的比例居中裁剪。
+func Resize(img image.Image, v Variant) image.Image {
+	b := img.Bounds()
+	w, h := v.Size(b.Dx(), b.Dy())
+	if v.Mode == Mode_Fill {
+		// 裁剪出与目标比例相同的最大区域
+		cw, ch := b.Dx(), b.Dy()
+		if cw*h > ch*w {
+			cw = max(1, ch*w/h)
+		} else {
+			ch = max(1, cw*h/w)
+		}
+		x0 := b.Min.X + (b.Dx()-cw)/2
+		y0 := b.Min.Y + (b.Dy()-ch)/2
+		b = image.Rect(x0, y0, x0+cw, y0+ch)
+	}
+	return resample(toRGBA(img, b), w, h)
+}
+
+// Encode 按格式编码图片，编码后的图片不包含EXIF等元数据。
+// 编码为JPEG时，透明的部分以白色填充。
+func Encode(w io.Writer, img image.Image, format string, quality int) error {
+	switch format {
+	case Format_JPEG:
+		if quality <= 0 {
+			quality = Default_Quality
+		}
+		return jpeg.Encode(w, flatten(img), &jpeg.Options{Quality: quality})
+	case Format_PNG:
+		return png.Encode(w, img)
+	case Format_GIF:
+		return gif.Encode(w, img, nil)
+	}
+	return genError(fmt.Sprintf("unsupported format %s", format))
+}
+
+// ContentType 图片格式对应的MIME类型。
+func ContentType(format string) string {
+	return "image/" + format
+}
+
+// Extension 图片格式对应的扩展名。
+func Extension(format string) string {
+	if format == Format_JPEG {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+var (
+	validName = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+	validSize = regexp.MustCompile(`^(\d*)x(\d*)$`)
+)
+
+// ParseVariants 解析逗号分隔的变体配置，格式为 名称:宽x高[:缩放方式][:格式]，
+// 如 thumb:200x200:fill,medium:800x0。
+func ParseVariants(s string) ([]Variant, error) {
+	var variants []Variant
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) == 0 {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		if len(parts) < 2 || len(parts) > 4 || !validName.MatchString(parts[0]) {
+			return nil, genError(fmt.Sprintf("invalid variant %q", item))
+		}
+		m := validSize.FindStringSubmatch(parts[1])
+		if m == nil {
+			return nil, genError(fmt.Sprintf("invalid variant size %q", parts[1]))
+		}
+		v := Variant{Name: parts[0]}
+		v.Width, _ = strconv.Atoi(m[1])
+		v.Height, _ = strconv.Atoi(m[2])
+		for _, opt := range parts[2:] {
+			switch opt {
+			case Mode_Fit, Mode_Fill:
+				v.Mode = opt
+			case Format_JPEG, Format_PNG, Format_GIF:
+				v.Format = opt
+			default:
+				return nil, genError(fmt.Sprintf("invalid variant option %q", opt))
+			}
+		}
+		if v.Mode == Mode_Fill && (v.Width == 0 || v.Height == 0) {
+			return nil, genError(fmt.Sprintf("variant %s: fill needs width and height", v.Name))
+		}
+		variants = append(variants, v)
+	}
+	return variants, nil
+}
+
+// toRGBA 将图片的指定区域转换为左上角为原点的*image.RGBA。
+func toRGBA(img image.Image, r image.Rectangle) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, r.Min, draw.Src)
+	return dst
+}
+
+// flatten 以白色填充透明的部分。
+func flatten(img image.Image) image.Image {
+	if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(b)
+	draw.Draw(dst, b, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, b, img, b.Min, draw.Over)
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,106 @@
+package imaging
+
+import (
+	"image"
+	"math"
+)
+
+// resample 使用三角形（双线性）滤波缩放图片，缩小时按比例扩大滤波范围，
+// 使每个像素取原图对应区域的加权平均。在预乘alpha的颜色上计算，避免透明边缘的杂色。
+func resample(src *image.RGBA, w, h int) *image.RGBA {
+	sw, sh := src.Rect.Dx(), src.Rect.Dy()
+	if sw == w && sh == h {
+		return src
+	}
+
+	// 先横向缩放到 w x sh，再纵向缩放到 w x h
+	tmp := make([]float64, w*sh*4)
+	xw := weights(sw, w)
+	for y := 0; y < sh; y++ {
+		row := src.Pix[y*src.Stride:]
+		for x, ws := range xw {
+			var c [4]float64
+			for _, wt := range ws {
+				p := row[wt.index*4:]
+				for i := 0; i < 4; i++ {
+					c[i] += float64(p[i]) * wt.weight
+				}
+			}
+			copy(tmp[(y*w+x)*4:], c[:])
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	yw := weights(sh, h)
+	for y, ws := range yw {
+		for x := 0; x < w; x++ {
+			var c [4]float64
+			for _, wt := range ws {
+				p := tmp[(wt.index*w+x)*4:]
+				for i := 0; i < 4; i++ {
+					c[i] += p[i] * wt.weight
+				}
+			}
+			d := dst.Pix[y*dst.Stride+x*4:]
+			for i := 0; i < 4; i++ {
+				d[i] = clamp(c[i])
+			}
+			// 预乘alpha的颜色不能大于alpha
+			for i := 0; i < 3; i++ {
+				if d[i] > d[3] {
+					d[i] = d[3]
+				}
+			}
+		}
+	}
+	return dst
+}
+
+type weight struct {
+	index  int
+	weight float64
+}
+
+// weights 计算目标的每个像素对应的原图像素及权重，权重之和为1。
+func weights(srcSize, dstSize int) [][]weight {
+	scale := float64(srcSize) / float64(dstSize)
+	support := math.Max(scale, 1)
+	result := make([][]weight, dstSize)
+	for i := range result {
+		center := (float64(i)+0.5)*scale - 0.5
+		lo := int(math.Floor(center - support))
+		hi := int(math.Ceil(center + support))
+		var ws []weight
+		sum := 0.0
+		for j := lo; j <= hi; j++ {
+			wt := 1 - math.Abs(float64(j)-center)/support
+			if wt <= 0 {
+				continue
+			}
+			k := j
+			if k < 0 {
+				k = 0
+			} else if k >= srcSize {
+				k = srcSize - 1
+			}
+			ws = append(ws, weight{index: k, weight: wt})
+			sum += wt
+		}
+		for j := range ws {
+			ws[j].weight /= sum
+		}
+		result[i] = ws
+	}
+	return result
+}
+
+func clamp(v float64) uint8 {
+	v = math.Round(v)
+	if v < 0 {
+		return 0
+	}
+	if v > 255 {
+		return 255
+	}
+	return uint8(v)
+}
@@ -0,0 +1,270 @@
+package openapi
+
+import (
+	"encoding"
+	"fmt"
+	"letgo/binding"
+	"letgo/validation"
+	"mime/multipart"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	fileHeaderType      = reflect.TypeOf(multipart.FileHeader{})
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	paramTags           = []string{binding.Tag_Query, binding.Tag_Path, binding.Tag_Header, binding.Tag_Cookie}
+	schemaRefPrefix     = "#/components/schemas/"
+	refNameReplacements = strings.NewReplacer("[", "_", "]", "", "*", "", "/", "_", ".", "_")
+)
+
+// SchemaOf 生成类型的Schema，具名结构体保存到Components中并返回引用。
+// 结构体字段按json tag命名，validate tag转换为对应的约束，
+// 带有query、path、header、cookie tag的字段作为参数，不包含在Schema中。
+func (d *Document) SchemaOf(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	s := d.schemaOf(t)
+	if nullable && len(s.Ref) == 0 {
+		s.Nullable = true
+	}
+	return s
+}
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "string", Format: "duration"}
+	case fileHeaderType:
+		return &Schema{Type: "string", Format: "binary"}
+	}
+	if t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType) {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.SchemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.SchemaOf(t.Elem())}
+	case reflect.Struct:
+		if len(t.Name()) == 0 {
+			return d.structSchema(t)
+		}
+		if d.refNames == nil {
+			d.refNames = make(map[reflect.Type]string)
+		}
+		name, ok := d.refNames[t]
+		if !ok {
+			name = d.refName(t)
+			d.refNames[t] = name
+			// 先占位，避免递归引用的类型无限展开
+			d.Components.Schemas[name] = &Schema{}
+			*d.Components.Schemas[name] = *d.structSchema(t)
+		}
+		return &Schema{Ref: schemaRefPrefix + name}
+	}
+	return &Schema{}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.addFields(s, t)
+	return s
+}
+
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 && !sf.Anonymous {
+			continue
+		}
+		if IsParamField(sf) {
+			continue
+		}
+
+		name, omit := jsonName(sf)
+		if omit {
+			continue
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if sf.Anonymous && len(name) == 0 && ft.Kind() == reflect.Struct {
+			d.addFields(s, ft)
+			continue
+		}
+		if len(name) == 0 {
+			name = sf.Name
+		}
+
+		fs := d.SchemaOf(sf.Type)
+		if ApplyRules(fs, sf.Tag.Get(validation.Tag_Validate)) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+}
+
+// FormSchemaOf 生成表单的Schema，只包含带有form tag的字段。
+func (d *Document) FormSchemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, ok := sf.Tag.Lookup(binding.Tag_Form)
+		if !ok || name == "-" || len(sf.PkgPath) > 0 {
+			continue
+		}
+		if name = strings.Split(name, ",")[0]; len(name) == 0 {
+			name = sf.Name
+		}
+		fs := d.SchemaOf(sf.Type)
+		if ApplyRules(fs, sf.Tag.Get(validation.Tag_Validate)) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+	if len(s.Properties) == 0 {
+		return nil
+	}
+	return s
+}
+
+// ApplyRules 将validate tag中的规则转换为Schema的约束，返回字段是否必填。
+func ApplyRules(s *Schema, tag string) (required bool) {
+	if len(tag) == 0 || tag == "-" {
+		return false
+	}
+	for _, rule := range strings.Split(tag, ",") {
+		name, param := rule, ""
+		if i := strings.IndexByte(rule, '='); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+		if name == "required" {
+			required = true
+			continue
+		}
+		// 引用的Schema是共享的，不添加字段的约束
+		if len(s.Ref) > 0 {
+			continue
+		}
+		switch name {
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "oneof":
+			for _, option := range strings.Fields(param) {
+				s.Enum = append(s.Enum, option)
+			}
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(s, name, n)
+		}
+	}
+	return
+}
+
+func applyBound(s *Schema, rule string, n float64) {
+	i := int(n)
+	switch s.Type {
+	case "string":
+		if rule != "max" {
+			s.MinLength = &i
+		}
+		if rule != "min" {
+			s.MaxLength = &i
+		}
+	case "array":
+		if rule != "max" {
+			s.MinItems = &i
+		}
+		if rule != "min" {
+			s.MaxItems = &i
+		}
+	case "integer", "number":
+		if rule != "max" {
+			s.Minimum = &n
+		}
+		if rule != "min" {
+			s.Maximum = &n
+		}
+	}
+}
+
+// IsParamField 字段是否从query、path、header、cookie中绑定。
+func IsParamField(sf reflect.StructField) bool {
+	for _, tag := range paramTags {
+		if v, ok := sf.Tag.Lookup(tag); ok && v != "-" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonName 字段的json名称，omit表示字段不参与序列化。
+func jsonName(sf reflect.StructField) (name string, omit bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	return strings.Split(tag, ",")[0], false
+}
+
+// refName 结构体在Components中的名称，泛型等特殊字符替换为下划线。
+// 不同包中的同名结构体，如 user.Request 和 order.Request，后生成的加上包名，
+// 包名也相同时使用完整的包路径。
+func (d *Document) refName(t reflect.Type) string {
+	candidates := []string{
+		t.Name(),
+		path.Base(t.PkgPath()) + "." + t.Name(),
+		t.PkgPath() + "." + t.Name(),
+	}
+	for _, c := range candidates {
+		name := refNameReplacements.Replace(c)
+		if _, ok := d.Components.Schemas[name]; !ok {
+			return name
+		}
+	}
+	// 完整的包路径替换特殊字符后仍然重复时加上序号
+	base := refNameReplacements.Replace(candidates[len(candidates)-1])
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s_%d", base, i)
+		if _, ok := d.Components.Schemas[name]; !ok {
+			return name
+		}
+	}
+}
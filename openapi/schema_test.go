@@ -0,0 +1,41 @@
+package openapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Request struct {
+	Name string `json:"name"`
+}
+
+func TestSchemaRefNames(t *testing.T) {
+	// 函数内定义的同名结构体，与包级的Request名称相同但类型不同
+	type Request struct {
+		ID int `json:"id"`
+	}
+
+	d := New("test", "1.0.0")
+	a := d.SchemaOf(reflect.TypeOf(Request{}))
+	b := d.SchemaOf(reflect.TypeOf(struct{ R Request }{}).Field(0).Type)
+	if a.Ref != b.Ref {
+		t.Errorf("same type: refs %s and %s differ", a.Ref, b.Ref)
+	}
+	c := d.SchemaOf(reflect.TypeOf(outerRequest()))
+	if c.Ref == a.Ref {
+		t.Fatalf("different types share ref %s", a.Ref)
+	}
+	if len(d.Components.Schemas) != 2 {
+		t.Errorf("schemas %d, want 2", len(d.Components.Schemas))
+	}
+	if _, ok := d.Components.Schemas["Request"].Properties["id"]; !ok {
+		t.Errorf("Request schema: %+v", d.Components.Schemas["Request"])
+	}
+	if _, ok := d.Components.Schemas["openapi_Request"].Properties["name"]; !ok {
+		t.Errorf("openapi_Request schema missing, got %v", d.Components.Schemas)
+	}
+}
+
+func outerRequest() Request {
+	return Request{}
+}
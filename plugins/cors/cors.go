@@ -40,20 +40,27 @@ func New() CORS {
 	c.allowAllOrigins = true
 	c.allowOrigins = []string{"http://localhost:4200"}
 	c.allowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "HEAD", "CONNECT", "TRACE", "PATCH"}
-	c.allowHeaders = []string{"Origin", "Authorization", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type", "x-requested-with", "Token"}
-	c.exposeHeaders = []string{"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type"}
+	c.allowHeaders = []string{"Origin", "Authorization", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type", "x-requested-with", "Token",
+		"Tus-Resumable", "Upload-Length", "Upload-Offset", "Upload-Metadata", "Upload-Checksum"}
+	c.exposeHeaders = []string{"Content-Length", "Access-Control-Allow-Origin", "Access-Control-Allow-Headers", "Content-Type",
+		"Location", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size", "Upload-Offset", "Upload-Length", "Upload-Metadata", "Upload-Expires", "Upload-File"}
 	c.allowCredentials = true
 	c.maxAge = time.Hour
 
 	return c
 }
 
+// isPreflight 是否为跨域的预检请求。
+func isPreflight(req *http.Request) bool {
+	return req.Method == "OPTIONS" &&
+		(req.Header.Get(AccessControlRequestMethod) != "" || req.Header.Get(AccessControlRequestHeaders) != "")
+}
+
 func (c *myCors) PrepareCors(resp http.ResponseWriter, req *http.Request) {
 	origin := req.Header.Get(HeaderOrigin)
 	requestMethod := req.Header.Get(AccessControlRequestMethod)
 	requestHeaders := req.Header.Get(AccessControlRequestHeaders)
-	if req.Method == "OPTIONS" &&
-		(requestMethod != "" || requestHeaders != "") {
+	if isPreflight(req) {
 		headers := c.preflightRequest(origin, requestMethod, requestHeaders)
 		for k, v := range headers {
 			resp.Header().Set(k, v)
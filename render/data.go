@@ -0,0 +1,19 @@
+package render
+
+const (
+	MIME_JSON        = "application/json"
+	MIME_JSONP       = "application/javascript"
+	MIME_XML         = "application/xml"
+	MIME_TextXML     = "text/xml"
+	MIME_YAML        = "application/yaml"
+	MIME_XYAML       = "application/x-yaml"
+	MIME_TextYAML    = "text/yaml"
+	MIME_MsgPack     = "application/msgpack"
+	MIME_XMsgPack    = "application/x-msgpack"
+	MIME_Text        = "text/plain"
+	MIME_HTML        = "text/html"
+	MIME_OctetStream = "application/octet-stream"
+)
+
+// Charset_UTF8 文本类型响应的字符集。
+const Charset_UTF8 = "; charset=utf-8"
@@ -0,0 +1,12 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errorPrefix = "render error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
@@ -0,0 +1,107 @@
+package render
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// field 参与编码的结构体字段。
+type field struct {
+	name  string
+	value reflect.Value
+}
+
+// structFields 结构体中参与编码的字段，与encoding/json一致：按json tag命名，
+// 忽略未导出的字段和 json:"-" 的字段，omitempty的字段为空时忽略，嵌入的结构体字段展开。
+func structFields(v reflect.Value) []field {
+	var fields []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 && !sf.Anonymous {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+		fv := v.Field(i)
+
+		if sf.Anonymous && len(name) == 0 {
+			ev := fv
+			if ev.Kind() == reflect.Ptr {
+				if ev.IsNil() {
+					continue
+				}
+				ev = ev.Elem()
+			}
+			if ev.Kind() == reflect.Struct && !isText(ev) {
+				fields = append(fields, structFields(ev)...)
+				continue
+			}
+			if len(sf.PkgPath) > 0 {
+				continue
+			}
+		}
+
+		if len(name) == 0 {
+			name = sf.Name
+		}
+		if hasOption(opts[1:], "omitempty") && isEmpty(fv) {
+			continue
+		}
+		fields = append(fields, field{name: name, value: fv})
+	}
+	return fields
+}
+
+func hasOption(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty 与encoding/json的omitempty一致。
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// isText 是否按字符串编码，如time.Time。
+func isText(v reflect.Value) bool {
+	return v.Type().Implements(textMarshalerType) || (v.CanAddr() && v.Addr().Type().Implements(textMarshalerType))
+}
+
+// marshalText 将实现了encoding.TextMarshaler的值编码为字符串。
+func marshalText(v reflect.Value) (string, error) {
+	m, ok := v.Interface().(encoding.TextMarshaler)
+	if !ok {
+		m = v.Addr().Interface().(encoding.TextMarshaler)
+	}
+	text, err := m.MarshalText()
+	return string(text), err
+}
+
+// sortedKeys 按字符串排序的map键，保证输出稳定。
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
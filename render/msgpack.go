@@ -0,0 +1,172 @@
+package render
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// MarshalMsgPack 将数据编码为MessagePack，结构体编码为映射，字段命名规则与encoding/json一致，
+// time.Time等实现了encoding.TextMarshaler的类型编码为字符串。
+func MarshalMsgPack(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := encodeMsgPack(&buf, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func encodeMsgPack(buf *bytes.Buffer, v reflect.Value) error {
+	if !v.IsValid() {
+		buf.WriteByte(0xc0)
+		return nil
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		buf.WriteByte(0xc0)
+		return nil
+	}
+	if isText(v) {
+		s, err := marshalText(v)
+		if err != nil {
+			return err
+		}
+		writeMsgPackString(buf, s)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return encodeMsgPack(buf, v.Elem())
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeMsgPackInt(buf, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeMsgPackUint(buf, v.Uint())
+	case reflect.Float32:
+		buf.WriteByte(0xca)
+		binary.Write(buf, binary.BigEndian, math.Float32bits(float32(v.Float())))
+	case reflect.Float64:
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(v.Float()))
+	case reflect.String:
+		writeMsgPackString(buf, v.String())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			buf.WriteByte(0xc0)
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			writeMsgPackHeader(buf, len(b), 0, 0xc4, 0xc5, 0xc6)
+			buf.Write(b)
+			return nil
+		}
+		writeMsgPackHeader(buf, v.Len(), 0x90, 0, 0xdc, 0xdd)
+		for i := 0; i < v.Len(); i++ {
+			if err := encodeMsgPack(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			buf.WriteByte(0xc0)
+			return nil
+		}
+		writeMsgPackHeader(buf, v.Len(), 0x80, 0, 0xde, 0xdf)
+		for _, key := range sortedKeys(v) {
+			if err := encodeMsgPack(buf, key); err != nil {
+				return err
+			}
+			if err := encodeMsgPack(buf, v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields := structFields(v)
+		writeMsgPackHeader(buf, len(fields), 0x80, 0, 0xde, 0xdf)
+		for _, f := range fields {
+			writeMsgPackString(buf, f.name)
+			if err := encodeMsgPack(buf, f.value); err != nil {
+				return err
+			}
+		}
+	default:
+		return genError(fmt.Sprintf("msgpack: unsupported type %s", v.Type()))
+	}
+	return nil
+}
+
+func writeMsgPackInt(buf *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0:
+		writeMsgPackUint(buf, uint64(n))
+	case n >= -32:
+		buf.WriteByte(byte(n))
+	case n >= math.MinInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(n))
+	case n >= math.MinInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(n))
+	case n >= math.MinInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(n))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func writeMsgPackUint(buf *bytes.Buffer, n uint64) {
+	switch {
+	case n <= 0x7f:
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint8:
+		buf.WriteByte(0xcc)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(0xcd)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(0xce)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(0xcf)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func writeMsgPackString(buf *bytes.Buffer, s string) {
+	if len(s) < 32 {
+		buf.WriteByte(0xa0 | byte(len(s)))
+	} else {
+		writeMsgPackHeader(buf, len(s), 0, 0xd9, 0xda, 0xdb)
+	}
+	buf.WriteString(s)
+}
+
+// writeMsgPackHeader 写入长度头：fix为0时不使用fix格式（长度小于16），
+// b8为0时不使用8位长度格式。
+func writeMsgPackHeader(buf *bytes.Buffer, n int, fix, b8, b16, b32 byte) {
+	switch {
+	case fix != 0 && n < 16:
+		buf.WriteByte(fix | byte(n))
+	case b8 != 0 && n <= math.MaxUint8:
+		buf.WriteByte(b8)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(b16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(b32)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
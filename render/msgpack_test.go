@@ -0,0 +1,128 @@
+package render
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMsgPackGolden(t *testing.T) {
+	type item struct {
+		A int    `json:"a"`
+		B string `json:"b,omitempty"`
+		c int
+	}
+	tests := []struct {
+		value interface{}
+		want  string // 十六进制
+	}{
+		{nil, "c0"},
+		{false, "c2"},
+		{true, "c3"},
+
+		// 整数的各个长度边界
+		{0, "00"},
+		{127, "7f"},
+		{128, "cc80"},
+		{255, "ccff"},
+		{256, "cd0100"},
+		{65535, "cdffff"},
+		{65536, "ce00010000"},
+		{uint32(math.MaxUint32), "ceffffffff"},
+		{int64(math.MaxUint32 + 1), "cf0000000100000000"},
+		{uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{-1, "ff"},
+		{-32, "e0"},
+		{-33, "d0df"},
+		{-128, "d080"},
+		{-129, "d1ff7f"},
+		{-32768, "d18000"},
+		{-32769, "d2ffff7fff"},
+		{int64(math.MinInt32), "d280000000"},
+		{int64(math.MinInt32 - 1), "d3ffffffff7fffffff"},
+		{int64(math.MinInt64), "d38000000000000000"},
+
+		{float32(1.5), "ca3fc00000"},
+		{1.5, "cb3ff8000000000000"},
+
+		{"", "a0"},
+		{"a", "a161"},
+		{[]byte{}, "c400"},
+		{[]byte{1, 2}, "c4020102"},
+		{[3]byte{1, 2, 3}, "c403010203"},
+		{[]int{}, "90"},
+		{[]interface{}{1, "a", nil}, "9301a161c0"},
+		{[]int(nil), "c0"},
+		{map[string]int{}, "80"},
+		{map[string]int{"b": 2, "a": 1}, "82a16101a16202"},
+		{map[string]int(nil), "c0"},
+		{item{A: 1}, "81a16101"},
+		{&item{A: 1, B: "x"}, "82a16101a162a178"},
+		{time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), "b4" + hex.EncodeToString([]byte("2024-05-06T07:08:09Z"))},
+	}
+	for _, tt := range tests {
+		got, err := MarshalMsgPack(tt.value)
+		if err != nil {
+			t.Errorf("MarshalMsgPack(%#v): %v", tt.value, err)
+			continue
+		}
+		if h := hex.EncodeToString(got); h != tt.want {
+			t.Errorf("MarshalMsgPack(%#v) = %s, want %s", tt.value, h, tt.want)
+		}
+	}
+}
+
+func TestMsgPackLengths(t *testing.T) {
+	list := func(n int) []int { return make([]int, n) }
+	dict := func(n int) map[int]bool {
+		m := make(map[int]bool, n)
+		for i := 0; i < n; i++ {
+			m[i] = true
+		}
+		return m
+	}
+	tests := []struct {
+		name   string
+		value  interface{}
+		header string // 十六进制的类型和长度头
+		size   int    // 编码后的总长度
+	}{
+		{"fixstr 31", strings.Repeat("s", 31), "bf", 1 + 31},
+		{"str8 32", strings.Repeat("s", 32), "d920", 2 + 32},
+		{"str8 255", strings.Repeat("s", 255), "d9ff", 2 + 255},
+		{"str16 256", strings.Repeat("s", 256), "da0100", 3 + 256},
+		{"str16 65535", strings.Repeat("s", 65535), "daffff", 3 + 65535},
+		{"str32 65536", strings.Repeat("s", 65536), "db00010000", 5 + 65536},
+		{"bin8 255", make([]byte, 255), "c4ff", 2 + 255},
+		{"bin16 256", make([]byte, 256), "c50100", 3 + 256},
+		{"bin16 65535", make([]byte, 65535), "c5ffff", 3 + 65535},
+		{"bin32 65536", make([]byte, 65536), "c600010000", 5 + 65536},
+		{"fixarray 15", list(15), "9f", 1 + 15},
+		{"array16 16", list(16), "dc0010", 3 + 16},
+		{"array16 65535", list(65535), "dcffff", 3 + 65535},
+		{"array32 65536", list(65536), "dd00010000", 5 + 65536},
+		{"fixmap 15", dict(15), "8f", -1},
+		{"map16 16", dict(16), "de0010", -1},
+	}
+	for _, tt := range tests {
+		got, err := MarshalMsgPack(tt.value)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		header, _ := hex.DecodeString(tt.header)
+		if !bytes.HasPrefix(got, header) {
+			t.Errorf("%s: header %x, want %s", tt.name, got[:len(header)], tt.header)
+		}
+		if tt.size >= 0 && len(got) != tt.size {
+			t.Errorf("%s: size %d, want %d", tt.name, len(got), tt.size)
+		}
+	}
+
+	if _, err := MarshalMsgPack(func() {}); err == nil {
+		t.Error("MarshalMsgPack(func): want error")
+	}
+}
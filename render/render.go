@@ -0,0 +1,166 @@
+package render
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MarshalFunc 将数据编码为响应内容。
+type MarshalFunc func(v interface{}) ([]byte, error)
+
+// Encoder 响应内容的编码器。
+type Encoder struct {
+	MediaType   string // 匹配Accept请求头的媒体类型，如 application/json
+	ContentType string // 响应的Content-Type，为空时使用MediaType
+	Marshal     MarshalFunc
+}
+
+// Type 响应的Content-Type。
+func (e Encoder) Type() string {
+	if len(e.ContentType) == 0 {
+		return e.MediaType
+	}
+	return e.ContentType
+}
+
+var (
+	// 按注册顺序匹配，Accept无法匹配时使用第一个
+	encoders = []Encoder{
+		{MediaType: MIME_JSON, ContentType: MIME_JSON + Charset_UTF8, Marshal: marshalJSON},
+		{MediaType: MIME_XML, ContentType: MIME_XML + Charset_UTF8, Marshal: xml.Marshal},
+		{MediaType: MIME_TextXML, ContentType: MIME_TextXML + Charset_UTF8, Marshal: xml.Marshal},
+		{MediaType: MIME_YAML, ContentType: MIME_YAML + Charset_UTF8, Marshal: MarshalYAML},
+		{MediaType: MIME_XYAML, ContentType: MIME_XYAML + Charset_UTF8, Marshal: MarshalYAML},
+		{MediaType: MIME_TextYAML, ContentType: MIME_TextYAML + Charset_UTF8, Marshal: MarshalYAML},
+		{MediaType: MIME_MsgPack, Marshal: MarshalMsgPack},
+		{MediaType: MIME_XMsgPack, Marshal: MarshalMsgPack},
+	}
+	mu sync.RWMutex
+)
+
+// Register 注册编码器，如 application/x-protobuf，媒体类型已注册时panic。
+func Register(enc Encoder) {
+	if enc.Marshal == nil || len(enc.MediaType) == 0 {
+		panic(genError("register encoder without media type or marshal func"))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, e := range encoders {
+		if e.MediaType == enc.MediaType {
+			panic(genError(fmt.Sprintf("%s has registed", enc.MediaType)))
+		}
+	}
+	encoders = append(encoders, enc)
+}
+
+// Lookup 查找媒体类型的编码器。
+func Lookup(mediaType string) (Encoder, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for _, e := range encoders {
+		if e.MediaType == mediaType {
+			return e, true
+		}
+	}
+	return Encoder{}, false
+}
+
+// Default 默认的编码器，即JSON。
+func Default() Encoder {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return encoders[0]
+}
+
+// Negotiate 按Accept请求头选择编码器，无法匹配时使用JSON。
+func Negotiate(accept string) Encoder {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	offers := make([]string, len(encoders))
+	for i, e := range encoders {
+		offers[i] = e.MediaType
+	}
+	best := NegotiateType(accept, offers...)
+	for _, e := range encoders {
+		if e.MediaType == best {
+			return e
+		}
+	}
+	return encoders[0]
+}
+
+// NegotiateType 从offers中选择Accept请求头优先级最高的类型，无法匹配时返回第一个。
+// 每个类型使用匹配的最具体的范围的q值，如 application/xml 优先于 application/* 和 */*，
+// q值相同时按offers的顺序选择。第一个类型为服务端的首选，只通过 */* 匹配时按最高的优先级处理，
+// 因此浏览器的 text/html,application/xml;q=0.9,*/*;q=0.8 选择第一个类型，
+// 其它类型只有在客户端明确列出第一个类型并给出更低的q值，或不接受第一个类型时才会被选择。
+func NegotiateType(accept string, offers ...string) string {
+	type acceptRange struct {
+		mediaType string
+		q         float64
+	}
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		ranges = append(ranges, acceptRange{mediaType, q})
+	}
+
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			if s := matchMediaType(r.mediaType, offer); s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+		if offer == offers[0] && specificity == 0 && q > 0 {
+			q = 1
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+// matchMediaType 判断Accept中的类型是否匹配，支持 */* 和 text/* 的写法，
+// 返回匹配的具体程度：完全匹配为2，text/* 为1，*/* 为0，不匹配为-1。
+func matchMediaType(pattern, mediaType string) int {
+	switch {
+	case pattern == mediaType:
+		return 2
+	case pattern == "*/*":
+		return 0
+	case strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, pattern[:len(pattern)-1]):
+		return 1
+	}
+	return -1
+}
+
+// marshalJSON 与json.Encoder一致，以换行结尾。
+func marshalJSON(v interface{}) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(body, '\n'), nil
+}
@@ -0,0 +1,27 @@
+package render
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", MIME_JSON},
+		{"*/*", MIME_JSON},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8", MIME_JSON},
+		{"application/xml", MIME_XML},
+		{"application/xml, application/json;q=0.5", MIME_XML},
+		{"application/xml;q=0.5, application/json", MIME_JSON},
+		{"application/json;q=0, */*", MIME_XML},
+		{"application/*", MIME_JSON},
+		{"text/*", MIME_TextXML},
+		{"application/yaml;q=0.9, application/msgpack", MIME_MsgPack},
+		{"image/png", MIME_JSON},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.accept).MediaType; got != tt.want {
+			t.Errorf("Negotiate(%q) = %s, want %s", tt.accept, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package render
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MarshalYAML 将数据编码为YAML块格式，字段命名规则与encoding/json一致。
+func MarshalYAML(v interface{}) ([]byte, error) {
+	scalar, lines, err := yamlOf(reflect.ValueOf(v))
+	if err != nil {
+		return nil, err
+	}
+	if lines == nil {
+		return []byte(scalar + "\n"), nil
+	}
+	return []byte(strings.Join(lines, "\n") + "\n"), nil
+}
+
+// yamlOf 返回值的YAML表示，标量返回scalar，映射和列表返回不含缩进的各行。
+func yamlOf(v reflect.Value) (scalar string, lines []string, err error) {
+	if !v.IsValid() {
+		return "null", nil, nil
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "null", nil, nil
+	}
+	if isText(v) {
+		s, err := marshalText(v)
+		return yamlString(s), nil, err
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return yamlOf(v.Elem())
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil, nil
+	case reflect.Float32, reflect.Float64:
+		return yamlFloat(v.Float(), v.Type().Bits()), nil, nil
+	case reflect.String:
+		return yamlString(v.String()), nil, nil
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return "null", nil, nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return "!!binary " + base64.StdEncoding.EncodeToString(b), nil, nil
+		}
+		if v.Len() == 0 {
+			return "[]", nil, nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			s, ls, err := yamlOf(v.Index(i))
+			if err != nil {
+				return "", nil, err
+			}
+			lines = appendItem(lines, "- ", s, ls)
+		}
+		return "", lines, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return "null", nil, nil
+		}
+		if v.Len() == 0 {
+			return "{}", nil, nil
+		}
+		for _, key := range sortedKeys(v) {
+			s, ls, err := yamlOf(v.MapIndex(key))
+			if err != nil {
+				return "", nil, err
+			}
+			lines = appendEntry(lines, yamlString(fmt.Sprint(key.Interface())), s, ls)
+		}
+		return "", lines, nil
+	case reflect.Struct:
+		fields := structFields(v)
+		if len(fields) == 0 {
+			return "{}", nil, nil
+		}
+		for _, f := range fields {
+			s, ls, err := yamlOf(f.value)
+			if err != nil {
+				return "", nil, err
+			}
+			lines = appendEntry(lines, yamlString(f.name), s, ls)
+		}
+		return "", lines, nil
+	}
+	return "", nil, genError(fmt.Sprintf("yaml: unsupported type %s", v.Type()))
+}
+
+// appendItem 添加列表项，块的后续行缩进到与第一行对齐。
+func appendItem(lines []string, prefix, scalar string, block []string) []string {
+	if block == nil {
+		return append(lines, prefix+scalar)
+	}
+	lines = append(lines, prefix+block[0])
+	for _, l := range block[1:] {
+		lines = append(lines, "  "+l)
+	}
+	return lines
+}
+
+// appendEntry 添加映射项，块另起一行缩进。
+func appendEntry(lines []string, key, scalar string, block []string) []string {
+	if block == nil {
+		return append(lines, key+": "+scalar)
+	}
+	lines = append(lines, key+":")
+	for _, l := range block {
+		lines = append(lines, "  "+l)
+	}
+	return lines
+}
+
+func yamlFloat(f float64, bits int) string {
+	switch {
+	case math.IsNaN(f):
+		return ".nan"
+	case math.IsInf(f, 1):
+		return ".inf"
+	case math.IsInf(f, -1):
+		return "-.inf"
+	}
+	s := strconv.FormatFloat(f, 'g', -1, bits)
+	if !strings.ContainsAny(s, ".eEn") {
+		s += ".0"
+	}
+	return s
+}
+
+var yamlReserved = map[string]bool{
+	"true": true, "false": true, "yes": true, "no": true, "on": true, "off": true,
+	"y": true, "n": true, "null": true, "~": true,
+}
+
+// yamlString 字符串会被解析为其它类型或包含特殊字符时使用双引号。
+func yamlString(s string) string {
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuote(s string) bool {
+	if len(s) == 0 || yamlReserved[strings.ToLower(s)] {
+		return true
+	}
+	// YAML的浮点数特殊值，如 .inf、-.Inf、.NaN
+	if f := strings.TrimLeft(strings.ToLower(s), "+-"); f == ".inf" || f == ".nan" {
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if _, err := strconv.ParseInt(s, 0, 64); err == nil {
+		return true
+	}
+	if strings.ContainsRune("-?:,[]{}#&*!|>'\"%@` ", rune(s[0])) || s[len(s)-1] == ' ' {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f || !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package render
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestYAMLScalars(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, "null"},
+		{true, "true"},
+		{-7, "-7"},
+		{uint8(255), "255"},
+		{1.0, "1.0"},
+		{1.5, "1.5"},
+		{1e21, "1e+21"},
+		{float32(0.1), "0.1"},
+		{math.NaN(), ".nan"},
+		{math.Inf(1), ".inf"},
+		{math.Inf(-1), "-.inf"},
+		{[]byte("hi"), "!!binary aGk="},
+		{time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC), "2024-05-06T07:08:09Z"},
+
+		// 普通字符串不加引号
+		{"plain text", "plain text"},
+		{"中文", "中文"},
+		{"a:b", "a:b"},
+		{"a#b", "a#b"},
+		{"x-y", "x-y"},
+
+		// 会被解析为其它类型的字符串
+		{"", `""`},
+		{"true", `"true"`},
+		{"Yes", `"Yes"`},
+		{"OFF", `"OFF"`},
+		{"n", `"n"`},
+		{"null", `"null"`},
+		{"~", `"~"`},
+		{"123", `"123"`},
+		{"0x1F", `"0x1F"`},
+		{"0o17", `"0o17"`},
+		{"1_000", `"1_000"`},
+		{"1e3", `"1e3"`},
+		{"-1.5", `"-1.5"`},
+		{"NaN", `"NaN"`},
+		{".inf", `".inf"`},
+		{"-.Inf", `"-.Inf"`},
+		{"+.inf", `"+.inf"`},
+		{".NaN", `".NaN"`},
+
+		// 特殊字符开头或含有特殊序列
+		{"- item", `"- item"`},
+		{"-", `"-"`},
+		{"? key", `"? key"`},
+		{": x", `": x"`},
+		{"#comment", `"#comment"`},
+		{"&anchor", `"&anchor"`},
+		{"*alias", `"*alias"`},
+		{"!tag", `"!tag"`},
+		{"|", `"|"`},
+		{">", `">"`},
+		{"@at", `"@at"`},
+		{"`tick", "\"`tick\""},
+		{"%dir", `"%dir"`},
+		{"[a]", `"[a]"`},
+		{"{a}", `"{a}"`},
+		{",a", `",a"`},
+		{"'single'", `"'single'"`},
+		{`"double"`, `"\"double\""`},
+		{" lead", `" lead"`},
+		{"trail ", `"trail "`},
+		{"key: value", `"key: value"`},
+		{"value #comment", `"value #comment"`},
+		{"key:", `"key:"`},
+
+		// 控制字符转义
+		{"line\nbreak", `"line\nbreak"`},
+		{"tab\there", `"tab\there"`},
+		{"back\\slash\n", `"back\\slash\n"`},
+		{"nul\x00", `"nul\x00"`},
+		{"del\x7f", `"del\x7f"`},
+		{"bell\a", `"bell\a"`},
+		{"nbsp\u00a0", `"nbsp\u00a0"`},
+		{"sep\u2028", `"sep\u2028"`},
+	}
+	for _, tt := range tests {
+		got, err := MarshalYAML(tt.value)
+		if err != nil {
+			t.Errorf("MarshalYAML(%#v): %v", tt.value, err)
+			continue
+		}
+		if string(got) != tt.want+"\n" {
+			t.Errorf("MarshalYAML(%#v) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+type yamlItem struct {
+	Name  string            `json:"name"`
+	Tags  []string          `json:"tags"`
+	Attrs map[string]string `json:"attrs,omitempty"`
+	Note  *string           `json:"note"`
+}
+
+type yamlDoc struct {
+	Title  string                 `json:"title"`
+	Items  []yamlItem             `json:"items"`
+	Matrix [][]int                `json:"matrix"`
+	Meta   map[string]interface{} `json:"meta"`
+	Empty  []int                  `json:"empty"`
+	None   map[string]int         `json:"none"`
+	Blank  struct{}               `json:"blank"`
+	hidden int
+}
+
+func TestYAMLNested(t *testing.T) {
+	doc := yamlDoc{
+		Title: "report: 2024",
+		Items: []yamlItem{
+			{Name: "a", Tags: []string{"x", "yes"}, Attrs: map[string]string{"k": "v", "1": "one"}},
+			{Name: "b", Tags: []string{}},
+		},
+		Matrix: [][]int{{1, 2}, {}, {3}},
+		Meta: map[string]interface{}{
+			"list":   []interface{}{map[string]int{"n": 1}, "s", nil},
+			"nested": map[string]interface{}{"deep": map[string]bool{"ok": true}},
+			"key: x": "needs quoting",
+		},
+		Empty: []int{},
+	}
+	want := `title: "report: 2024"
+items:
+  - name: a
+    tags:
+      - x
+      - "yes"
+    attrs:
+      "1": one
+      k: v
+    note: null
+  - name: b
+    tags: []
+    note: null
+matrix:
+  - - 1
+    - 2
+  - []
+  - - 3
+meta:
+  "key: x": needs quoting
+  list:
+    - "n": 1
+    - s
+    - null
+  nested:
+    deep:
+      ok: true
+empty: []
+none: null
+blank: {}
+`
+	got, err := MarshalYAML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("MarshalYAML:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := MarshalYAML(map[string]interface{}{"ch": make(chan int)}); err == nil {
+		t.Error("MarshalYAML(chan): want error")
+	}
+}
@@ -1,17 +1,31 @@
 package router
 
+// 路由的默认配置，见RouterOptions。
 const (
 	Static_Folder = "www"
 	Project_Name  = "my-zone"
 	Homepage      = "index.html"
+	Views_Folder  = "views"
+	Tus_Folder    = "tus"
 
 	Prefix_API    = "/api"
 	Prefix_Static = "/www"
 	Prefix_Upload = "/upload"
+	Prefix_Tus    = "/upload/tus"
 
 	Suffix_Controller = "Controller"
+
+	// OpenAPI_UIAssets 文档查看页面的swagger-ui资源目录，位于静态资源目录下，
+	// 将swagger-ui-dist中的swagger-ui.css和swagger-ui-bundle.js放到 <StaticFolder>/swagger-ui
+	OpenAPI_UIAssets = "swagger-ui"
 )
 
+// Tag_Inject 控制器字段的tag，见Router.Provide和Router.ProvideNamed。
+const Tag_Inject = "inject"
+
+// methodAny 不限HTTP方法的路由。
+const methodAny = "*"
+
 var (
 	HTTPMethods = []string{
 		"CONNECT",
@@ -25,4 +39,8 @@ var (
 		"PUT",
 		"TRACE",
 	}
+
+	// 自动路由中表示HTTP方法的方法名前缀，如 GetUser 只响应 GET /api/<控制器>/user，
+	// 方法名为 Get 时只响应 GET /api/<控制器>。
+	verbPrefixes = []string{"Get", "Post", "Put", "Delete", "Patch", "Head", "Options"}
 )
@@ -1,5 +1,111 @@
 package router
 
-func genError() {
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"letgo/context"
+	"net/http"
+)
 
+var errorPrefix = "router error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
+
+// HTTPError 带HTTP状态码的错误，响应格式参考RFC 7807。
+type HTTPError struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+
+	// Errors 扩展的错误详情，如参数校验失败的字段列表。
+	Errors interface{} `json:"errors,omitempty"`
+
+	Err error `json:"-"` // 内部错误，只记录日志，不返回给客户端
+}
+
+// NewHTTPError 创建HTTP错误，detail为返回给客户端的错误说明。
+func NewHTTPError(status int, detail string) *HTTPError {
+	return &HTTPError{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%d %s: %v", e.Status, e.Title, e.Err)
+	}
+	if len(e.Detail) > 0 {
+		return fmt.Sprintf("%d %s: %s", e.Status, e.Title, e.Detail)
+	}
+	return fmt.Sprintf("%d %s", e.Status, e.Title)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCoder 带HTTP状态码的错误，如upload.Error，
+// 转换为HTTPError时使用其状态码，错误信息作为Detail返回给客户端。
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// ToHTTPError 将错误转换为HTTPError，其它错误视为500内部错误。
+func ToHTTPError(err error) *HTTPError {
+	var e *HTTPError
+	if errors.As(err, &e) {
+		return e
+	}
+	var sc StatusCoder
+	if errors.As(err, &sc) {
+		e = NewHTTPError(sc.StatusCode(), err.Error())
+		e.Err = err
+		return e
+	}
+	e = NewHTTPError(http.StatusInternalServerError, "")
+	e.Err = err
+	return e
+}
+
+// ErrorRenderer 错误响应的渲染函数。
+type ErrorRenderer func(ctx context.Context, err error)
+
+// renderError 记录服务端错误的日志，并输出错误响应。
+func (r *myRouter) renderError(ctx context.Context, err error) {
+	if e := ToHTTPError(err); e.Status >= http.StatusInternalServerError && e.Err != nil {
+		if l := r.logger(); l != nil {
+			req := ctx.Request()
+			l.Error("%s %s: %v", req.Method, req.URL.Path, e.Err)
+		}
+	}
+	r.writeError(ctx, err)
+}
+
+// writeError 输出错误响应，配置了ErrorRenderer时由其处理，已写入响应时不再输出。
+func (r *myRouter) writeError(ctx context.Context, err error) {
+	if ctx.Response().Written() {
+		return
+	}
+	if r.options.ErrorRenderer != nil {
+		r.options.ErrorRenderer(ctx, err)
+		return
+	}
+
+	e := ToHTTPError(err)
+	contentType := "application/json; charset=utf-8"
+	if r.options.ProblemJSON {
+		contentType = "application/problem+json; charset=utf-8"
+	}
+	rw := ctx.Response()
+	rw.Header().Set("Content-Type", contentType)
+	rw.Header().Set("X-Content-Type-Options", "nosniff")
+	rw.WriteHeader(e.Status)
+	json.NewEncoder(rw).Encode(e)
 }
@@ -0,0 +1,165 @@
+package router
+
+import (
+	"fmt"
+	"letgo/controller"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Group 路由分组，分组内的路由共享路径前缀和中间件。
+type Group interface {
+	// AddAutoRouter 按控制器的方法自动注册路由，格式：<前缀>/<控制器>/<方法>，
+	// Router上的前缀为RouterOptions.PrefixAPI，分组上的前缀为分组前缀。
+	AddAutoRouter(c controller.Controller, middlewares ...interface{})
+	AddRouter(pattern string, c controller.Controller, methodName string, middlewares ...interface{}) Route
+
+	// 注册指定HTTP方法的路由，handler的类型见HandlerFunc相关说明，
+	// middlewares只作用于该路由，类型见Middleware相关说明。
+	Get(pattern string, handler interface{}, middlewares ...interface{}) Route
+	Post(pattern string, handler interface{}, middlewares ...interface{}) Route
+	Put(pattern string, handler interface{}, middlewares ...interface{}) Route
+	Delete(pattern string, handler interface{}, middlewares ...interface{}) Route
+	Patch(pattern string, handler interface{}, middlewares ...interface{}) Route
+	// Handle 注册不限HTTP方法的路由。
+	Handle(pattern string, handler interface{}, middlewares ...interface{}) Route
+
+	// Use 添加分组中间件，作用于之后在分组内注册的路由。
+	Use(middlewares ...interface{})
+	// Group 创建子分组，子分组继承当前分组的前缀和中间件。
+	Group(prefix string, middlewares ...interface{}) Group
+}
+
+type group struct {
+	router      *myRouter
+	prefix      string       // 路由格式的前缀
+	autoPrefix  string       // 自动路由的前缀
+	middlewares []Middleware // 分组中间件
+}
+
+func (g *group) Group(prefix string, middlewares ...interface{}) Group {
+	prefix = path.Join("/", g.prefix, prefix)
+	mws := make([]Middleware, 0, len(g.middlewares)+len(middlewares))
+	mws = append(mws, g.middlewares...)
+	mws = append(mws, toMiddlewares(middlewares)...)
+	return &group{
+		router:      g.router,
+		prefix:      prefix,
+		autoPrefix:  prefix,
+		middlewares: mws,
+	}
+}
+
+func (g *group) Use(middlewares ...interface{}) {
+	g.middlewares = append(g.middlewares, toMiddlewares(middlewares)...)
+}
+
+// AddAutoRouter 方法名以HTTP方法开头时只响应该方法，见verbPrefixes。
+// 注册的方法见autoMethods，可以通过controller.ActionLister或controller.MethodExcluder指定。
+func (g *group) AddAutoRouter(c controller.Controller, middlewares ...interface{}) {
+	ct := reflect.Indirect(reflect.ValueOf(c)).Type()
+	controllerName := strings.TrimSuffix(ct.Name(), g.router.options.SuffixController)
+	mws := toMiddlewares(middlewares)
+
+	for _, m := range g.router.autoMethods(c) {
+		verb, action := splitVerb(m.Name)
+		pattern := path.Join(g.autoPrefix, strings.ToLower(controllerName), strings.ToLower(action))
+		route := &route{pattern: pattern}
+		g.router.setControllerMethod(route, ct, m.Name, m.Func)
+		g.addRoute([]string{verb}, route, mws)
+	}
+}
+
+// AddRouter 将控制器的方法注册到指定的路由格式，路由格式可以包含路径参数。
+// methodName可以指定响应的HTTP方法，如 "get,post:Login"，未指定时不限方法。
+func (g *group) AddRouter(pattern string, c controller.Controller, methodName string, middlewares ...interface{}) Route {
+	verbs := []string{methodAny}
+	if i := strings.IndexByte(methodName, ':'); i >= 0 {
+		verbs = parseVerbs(methodName[:i])
+		methodName = methodName[i+1:]
+	}
+
+	reflectVal := reflect.ValueOf(c)
+	method, ok := reflectVal.Type().MethodByName(methodName)
+	if !ok {
+		panic(genError(fmt.Sprintf("%s has no method %s", reflectVal.Type(), methodName)))
+	}
+	route := &route{pattern: g.join(pattern)}
+	g.router.setControllerMethod(route, reflect.Indirect(reflectVal).Type(), method.Name, method.Func)
+	g.addRoute(verbs, route, toMiddlewares(middlewares))
+	return &namedRoute{router: g.router, route: route}
+}
+
+func (g *group) Get(pattern string, handler interface{}, middlewares ...interface{}) Route {
+	return g.handle(http.MethodGet, pattern, handler, middlewares)
+}
+
+func (g *group) Post(pattern string, handler interface{}, middlewares ...interface{}) Route {
+	return g.handle(http.MethodPost, pattern, handler, middlewares)
+}
+
+func (g *group) Put(pattern string, handler interface{}, middlewares ...interface{}) Route {
+	return g.handle(http.MethodPut, pattern, handler, middlewares)
+}
+
+func (g *group) Delete(pattern string, handler interface{}, middlewares ...interface{}) Route {
+	return g.handle(http.MethodDelete, pattern, handler, middlewares)
+}
+
+func (g *group) Patch(pattern string, handler interface{}, middlewares ...interface{}) Route {
+	return g.handle(http.MethodPatch, pattern, handler, middlewares)
+}
+
+func (g *group) Handle(pattern string, handler interface{}, middlewares ...interface{}) Route {
+	return g.handle(methodAny, pattern, handler, middlewares)
+}
+
+func (g *group) handle(verb, pattern string, handler interface{}, middlewares []interface{}) Route {
+	route := g.router.newHandlerRoute(g.join(pattern), handler)
+	g.addRoute([]string{verb}, route, toMiddlewares(middlewares))
+	return &namedRoute{router: g.router, route: route}
+}
+
+// join 拼接分组前缀和路由格式。
+func (g *group) join(pattern string) string {
+	if len(g.prefix) == 0 {
+		return pattern
+	}
+	return path.Join(g.prefix, pattern)
+}
+
+// addRoute 用分组中间件和路由中间件包装处理函数后，按HTTP方法添加到路由树。
+// 与已有路由冲突时不注册，错误记录到日志并可以通过Router.Errors获取；
+// 与已有的参数可能匹配相同的路径时记录警告，见node.insert。
+func (g *group) addRoute(verbs []string, route *route, middlewares []Middleware) {
+	route.handler = chain(chain(route.handler, middlewares), g.middlewares)
+	registered := false
+	l := g.router.logger()
+	for _, verb := range verbs {
+		overlaps, err := g.router.tree.insert(verb, route.pattern, route)
+		if l != nil {
+			for _, overlap := range overlaps {
+				l.Warn("%s: %s", route.describe(), overlap)
+			}
+		}
+		if err != nil {
+			g.router.errs = append(g.router.errs, err)
+			if l != nil {
+				l.Error("%v", err)
+			}
+			continue
+		}
+		registered = true
+		g.router.entries = append(g.router.entries, entry{method: verb, route: route})
+	}
+	// 控制器的路由默认以 控制器.方法 命名，名称已被使用时不命名
+	if registered && len(route.tag) > 0 && len(route.name) == 0 {
+		name := route.tag + "." + route.methodName
+		if _, ok := g.router.names[name]; !ok {
+			route.name = name
+			g.router.names[name] = route
+		}
+	}
+}
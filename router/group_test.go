@@ -0,0 +1,83 @@
+package router
+
+import (
+	"letgo/context"
+	"letgo/controller"
+	"net/http"
+	"testing"
+)
+
+type GroupController struct {
+	controller.Base
+}
+
+func (c *GroupController) Actions() []string {
+	return []string{"GetInfo"}
+}
+
+func (c *GroupController) GetInfo() {
+	c.ServeText("info " + c.Response().Header().Get("X-Group"))
+}
+
+// tag 设置响应头的中间件，值追加到已有的值后面。
+func tag(value string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context) {
+			h := ctx.Response().Header()
+			h.Set("X-Group", h.Get("X-Group")+value)
+			next(ctx)
+		}
+	}
+}
+
+func TestGroup(t *testing.T) {
+	r := newTestRouter()
+	ok := func(ctx context.Context) { ctx.Response().Write([]byte(ctx.Request().URL.Path)) }
+
+	v1 := r.Group("/v1", tag("a"))
+	v1.Get("/users/:id", ok)
+	v1.Get("before", ok)
+	v1.Use(tag("b"))
+	v1.Get("/after", ok)
+
+	admin := v1.Group("admin/", tag("c"))
+	admin.Get("/stats", ok)
+	// 子分组之后在父分组添加的中间件不作用于子分组
+	v1.Use(tag("d"))
+	admin.Get("/logs", ok)
+	admin.AddAutoRouter(&GroupController{})
+	admin.AddRouter("/info/:id", &GroupController{}, "get:GetInfo")
+
+	root := r.Group("/")
+	root.Get("/root", ok)
+
+	tests := []struct {
+		path   string
+		status int
+		tags   string
+	}{
+		{"/v1/users/1", http.StatusOK, "a"},
+		{"/v1/before", http.StatusOK, "a"},
+		{"/v1/after", http.StatusOK, "ab"},
+		{"/v1/admin/stats", http.StatusOK, "abc"},
+		{"/v1/admin/logs", http.StatusOK, "abc"},
+		{"/v1/admin/group/info", http.StatusOK, "abc"},
+		{"/v1/admin/info/3", http.StatusOK, "abc"},
+		{"/root", http.StatusOK, ""},
+		{"/users/1", http.StatusNotFound, ""},
+		{"/api/group/info", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rw := serve(r, http.MethodGet, tt.path)
+		if rw.Code != tt.status {
+			t.Errorf("GET %s: status %d, want %d", tt.path, rw.Code, tt.status)
+			continue
+		}
+		if got := rw.Header().Get("X-Group"); got != tt.tags {
+			t.Errorf("GET %s: middlewares %q, want %q", tt.path, got, tt.tags)
+		}
+	}
+	if rw := serve(r, http.MethodGet, "/v1/admin/group/info"); rw.Body.String() != "info abc" {
+		t.Errorf("auto route in group: body %q", rw.Body)
+	}
+}
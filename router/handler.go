@@ -0,0 +1,213 @@
+package router
+
+import (
+	"fmt"
+	"letgo/context"
+	"letgo/controller"
+	"letgo/validation"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// HandlerFunc 路由处理函数。
+type HandlerFunc func(ctx context.Context)
+
+var (
+	controllerType = reflect.TypeOf((*controller.Controller)(nil)).Elem()
+	baseType       = reflect.TypeOf(controller.Base{})
+
+	// 自动路由不注册这些接口的方法
+	hookTypes = []reflect.Type{
+		controllerType,
+		reflect.TypeOf((*controller.Preparer)(nil)).Elem(),
+		reflect.TypeOf((*controller.Finisher)(nil)).Elem(),
+		reflect.TypeOf((*controller.MethodExcluder)(nil)).Elem(),
+		reflect.TypeOf((*controller.ViewSetter)(nil)).Elem(),
+	}
+)
+
+// newHandlerRoute 根据处理器创建路由，支持的处理器类型：
+// http.Handler、func(http.ResponseWriter, *http.Request)、func(context.Context)、HandlerFunc，
+// 以及控制器的方法表达式，如 (*AccountController).Login，每次请求都会新建控制器。
+func (r *myRouter) newHandlerRoute(pattern string, handler interface{}) *route {
+	route := &route{pattern: pattern}
+
+	switch h := handler.(type) {
+	case HandlerFunc:
+		route.handler = h
+	case func(context.Context):
+		route.handler = h
+	case http.Handler:
+		route.handler = func(ctx context.Context) {
+			h.ServeHTTP(ctx.Response(), ctx.Request())
+		}
+	case func(http.ResponseWriter, *http.Request):
+		route.handler = func(ctx context.Context) {
+			h(ctx.Response(), ctx.Request())
+		}
+	default:
+		fn := reflect.ValueOf(handler)
+		ft := fn.Type()
+		if ft.Kind() != reflect.Func || ft.NumIn() == 0 || !ft.In(0).Implements(controllerType) || ft.In(0).Kind() != reflect.Ptr {
+			panic(genError(fmt.Sprintf("pattern %s: unsupported handler type %s", pattern, ft)))
+		}
+		r.setControllerMethod(route, ft.In(0).Elem(), funcName(fn), fn)
+	}
+
+	return route
+}
+
+// setControllerMethod 设置路由调用的控制器方法，method的第一个参数为控制器。
+func (r *myRouter) setControllerMethod(route *route, ct reflect.Type, methodName string, method reflect.Value) {
+	route.controllerType = ct
+	route.methodName = methodName
+	route.method = method
+	route.tag = strings.TrimSuffix(ct.Name(), r.options.SuffixController)
+	route.injects = injectFields(ct, nil)
+
+	// 提取方法的第一个参数信息，如果是Struct，则保存到路由信息，用户访问时请求数据绑定为Struct
+	mt := method.Type()
+
+	if mt.NumIn() >= 2 {
+		it := mt.In(1)
+		if it.Kind() == reflect.Struct {
+			route.methodInputType = it
+			if err := validation.CheckTags(it); err != nil {
+				panic(genError(fmt.Sprintf("%s.%s: %v", ct, methodName, err)))
+			}
+		}
+	}
+	// 第一个不是error的返回值作为输出
+	if mt.NumOut() > 0 && mt.Out(0) != errorType {
+		route.methodOutputType = mt.Out(0)
+	}
+
+	route.handler = func(ctx context.Context) {
+		r.serveController(route, ctx)
+	}
+}
+
+// funcName 获取方法表达式的方法名。
+func funcName(fn reflect.Value) string {
+	name := runtime.FuncForPC(fn.Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// autoMethods 自动路由注册的方法：
+// 控制器实现controller.ActionLister时只包含Actions返回的方法，
+// 否则不包含Controller、Preparer、Finisher等接口的方法，controller.Base的方法，
+// 以及ExcludeMethods返回的方法。
+// 其余签名不能作为路由的方法不注册，并输出警告。
+// 控制器既未实现ActionLister也未实现MethodExcluder时，每个注册的方法都输出警告，
+// 避免CurrentUser等辅助方法在不知情时成为路由。
+func (r *myRouter) autoMethods(c controller.Controller) []reflect.Method {
+	rt := reflect.TypeOf(c)
+	ct := reflect.Indirect(reflect.ValueOf(c)).Type()
+
+	if lister, ok := c.(controller.ActionLister); ok {
+		var methods []reflect.Method
+		for _, name := range lister.Actions() {
+			m, ok := rt.MethodByName(name)
+			if !ok {
+				panic(genError(fmt.Sprintf("%s has no method %s", rt, name)))
+			}
+			if !isActionMethod(m.Type) {
+				panic(genError(fmt.Sprintf("%s.%s: unsupported method signature %s", ct, name, m.Type)))
+			}
+			methods = append(methods, m)
+		}
+		return methods
+	}
+
+	excluded := make(map[string]bool)
+	for _, t := range hookTypes {
+		for i := 0; i < t.NumMethod(); i++ {
+			excluded[t.Method(i).Name] = true
+		}
+	}
+	if embedsBase(ct) {
+		pt := reflect.PtrTo(baseType)
+		for i := 0; i < pt.NumMethod(); i++ {
+			excluded[pt.Method(i).Name] = true
+		}
+	}
+	excluder, declared := c.(controller.MethodExcluder)
+	if declared {
+		for _, name := range excluder.ExcludeMethods() {
+			excluded[name] = true
+		}
+	}
+
+	var methods []reflect.Method
+	for i := 0; i < rt.NumMethod(); i++ {
+		m := rt.Method(i)
+		if excluded[m.Name] {
+			continue
+		}
+		if !isActionMethod(m.Type) {
+			if l := r.logger(); l != nil {
+				l.Warn("%s.%s is not registered as a route: unsupported method signature %s, exclude it with ExcludeMethods", ct, m.Name, m.Type)
+			}
+			continue
+		}
+		methods = append(methods, m)
+	}
+
+	// 未声明Actions和ExcludeMethods时，辅助方法也会注册为路由，逐个提示
+	if !declared {
+		if l := r.logger(); l != nil {
+			for _, m := range methods {
+				l.Warn("%s.%s is registered as a route, declare Actions or ExcludeMethods on %s to control which methods are exposed", ct, m.Name, ct)
+			}
+		}
+	}
+	return methods
+}
+
+// isActionMethod 方法签名是否可以作为路由，mt的第一个参数为接收者：
+// 参数为空或一个结构体，返回值为空、一个值、一个error或一个值和一个error。
+func isActionMethod(mt reflect.Type) bool {
+	switch mt.NumIn() {
+	case 1:
+	case 2:
+		if mt.In(1).Kind() != reflect.Struct {
+			return false
+		}
+	default:
+		return false
+	}
+	switch mt.NumOut() {
+	case 0, 1:
+		return true
+	case 2:
+		return mt.Out(1) == errorType
+	}
+	return false
+}
+
+// embedsBase 结构体是否直接或间接嵌入了controller.Base。
+func embedsBase(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.Anonymous {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft == baseType || embedsBase(ft) {
+			return true
+		}
+	}
+	return false
+}
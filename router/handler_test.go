@@ -0,0 +1,91 @@
+package router
+
+import (
+	"letgo/context"
+	"letgo/controller"
+	"net/http"
+	"testing"
+)
+
+type HandlerController struct {
+	controller.Base
+}
+
+func (c *HandlerController) Show() {
+	c.ServeText("show " + c.Param("id"))
+}
+
+func TestRouteMethods(t *testing.T) {
+	r := newTestRouter()
+	reply := func(body string) HandlerFunc {
+		return func(ctx context.Context) { ctx.Response().Write([]byte(body)) }
+	}
+	r.Get("/res", reply("get"))
+	r.Post("/res", reply("post"))
+	r.Put("/res", reply("put"))
+	r.Delete("/res", reply("delete"))
+	r.Patch("/res", reply("patch"))
+	r.Handle("/any", reply("any"))
+	// 单独注册的方法优先，其它方法使用Handle注册的路由
+	r.Handle("/mixed", reply("any"))
+	r.Get("/mixed", reply("get"))
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{http.MethodGet, "/res", http.StatusOK, "get"},
+		{http.MethodPost, "/res", http.StatusOK, "post"},
+		{http.MethodPut, "/res", http.StatusOK, "put"},
+		{http.MethodDelete, "/res", http.StatusOK, "delete"},
+		{http.MethodPatch, "/res", http.StatusOK, "patch"},
+		{http.MethodGet, "/any", http.StatusOK, "any"},
+		{http.MethodDelete, "/any", http.StatusOK, "any"},
+		{"PROPFIND", "/any", http.StatusOK, "any"},
+		{http.MethodGet, "/mixed", http.StatusOK, "get"},
+		{http.MethodPost, "/mixed", http.StatusOK, "any"},
+		{"PROPFIND", "/res", http.StatusMethodNotAllowed, ""},
+		{http.MethodGet, "/missing", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rw := serve(r, tt.method, tt.path)
+		if rw.Code != tt.status || (tt.status == http.StatusOK && rw.Body.String() != tt.body) {
+			t.Errorf("%s %s: status %d %q, want %d %q", tt.method, tt.path, rw.Code, rw.Body, tt.status, tt.body)
+		}
+	}
+}
+
+func TestHandlerTypes(t *testing.T) {
+	r := newTestRouter()
+	r.Get("/func", func(ctx context.Context) { ctx.Response().Write([]byte("func")) })
+	r.Get("/handlerfunc", HandlerFunc(func(ctx context.Context) { ctx.Response().Write([]byte("handlerfunc")) }))
+	r.Get("/http", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) { rw.Write([]byte("http")) }))
+	r.Get("/stdfunc", func(rw http.ResponseWriter, req *http.Request) { rw.Write([]byte("stdfunc")) })
+	r.Get("/show/:id", (*HandlerController).Show)
+
+	tests := map[string]string{
+		"/func":        "func",
+		"/handlerfunc": "handlerfunc",
+		"/http":        "http",
+		"/stdfunc":     "stdfunc",
+		"/show/7":      "show 7",
+	}
+	for path, want := range tests {
+		if rw := serve(r, http.MethodGet, path); rw.Code != http.StatusOK || rw.Body.String() != want {
+			t.Errorf("GET %s: status %d %q, want %q", path, rw.Code, rw.Body, want)
+		}
+	}
+
+	for _, handler := range []interface{}{"not a handler", func(int) {}, func(*http.Request) {}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Get with handler %T: want panic", handler)
+				}
+			}()
+			r.Get("/bad", handler)
+		}()
+	}
+}
@@ -0,0 +1,93 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// injectField 控制器中需要注入服务的字段。
+type injectField struct {
+	index []int
+	name  string // 服务名称，为空时按类型注入
+	typ   reflect.Type
+}
+
+// Provide 注册按类型注入的服务，控制器中带有 inject:"" tag 的字段，
+// 注入类型相同或实现了字段接口类型的服务，有多个时使用先注册的。
+// 服务在请求间共享，应在处理请求前注册，并且可以并发使用。
+func (r *myRouter) Provide(services ...interface{}) {
+	for _, s := range services {
+		if s == nil {
+			panic(genError("provide nil service"))
+		}
+		r.services = append(r.services, reflect.ValueOf(s))
+	}
+}
+
+// ProvideNamed 注册按名称注入的服务，控制器中带有 inject:"<名称>" tag 的字段注入该服务。
+func (r *myRouter) ProvideNamed(name string, service interface{}) {
+	if len(name) == 0 || service == nil {
+		panic(genError("provide service without name or value"))
+	}
+	if _, ok := r.namedServices[name]; ok {
+		panic(genError(fmt.Sprintf("service %s has provided", name)))
+	}
+	r.namedServices[name] = reflect.ValueOf(service)
+}
+
+// injectFields 查找控制器中带有inject tag的字段，包括嵌入的结构体中的字段。
+func injectFields(t reflect.Type, parent []int) []injectField {
+	var fields []injectField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		index := append(append([]int{}, parent...), i)
+		name, ok := sf.Tag.Lookup(Tag_Inject)
+		if !ok {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				fields = append(fields, injectFields(sf.Type, index)...)
+			}
+			continue
+		}
+		if name == "-" {
+			continue
+		}
+		if len(sf.PkgPath) > 0 {
+			panic(genError(fmt.Sprintf("%s.%s: can not inject unexported field", t, sf.Name)))
+		}
+		fields = append(fields, injectField{index: index, name: name, typ: sf.Type})
+	}
+	return fields
+}
+
+// inject 将服务设置到控制器的字段。
+func (r *myRouter) inject(cv reflect.Value, fields []injectField) error {
+	for _, f := range fields {
+		s, ok := r.lookupService(f)
+		if !ok {
+			if len(f.name) > 0 {
+				return genError(fmt.Sprintf("%s: service %s not provided or not assignable to %s", cv.Type(), f.name, f.typ))
+			}
+			return genError(fmt.Sprintf("%s: no service of type %s provided", cv.Type(), f.typ))
+		}
+		cv.FieldByIndex(f.index).Set(s)
+	}
+	return nil
+}
+
+func (r *myRouter) lookupService(f injectField) (reflect.Value, bool) {
+	if len(f.name) > 0 {
+		s, ok := r.namedServices[f.name]
+		return s, ok && s.Type().AssignableTo(f.typ)
+	}
+	for _, s := range r.services {
+		if s.Type() == f.typ {
+			return s, true
+		}
+	}
+	for _, s := range r.services {
+		if s.Type().AssignableTo(f.typ) {
+			return s, true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,146 @@
+package router
+
+import (
+	"letgo/controller"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type english struct{}
+
+func (english) Greet() string { return "hello" }
+
+type french struct{}
+
+func (*french) Greet() string { return "bonjour" }
+
+// hookServices 嵌入的结构体中的字段也会注入。
+type hookServices struct {
+	French *french `inject:""`
+}
+
+// hookEvents 记录钩子的调用，作为服务注入。
+type hookEvents struct {
+	calls []string
+}
+
+type HookController struct {
+	controller.Base
+	hookServices
+	Greeter greeter     `inject:""`
+	Events  *hookEvents `inject:""`
+	Suffix  string      `inject:"suffix"`
+	Skipped greeter     `inject:"-"`
+}
+
+func (c *HookController) Prepare() error {
+	c.Events.calls = append(c.Events.calls, "prepare")
+	if len(c.Header("Authorization")) == 0 {
+		return NewHTTPError(http.StatusUnauthorized, "login required")
+	}
+	return nil
+}
+
+func (c *HookController) Finish() {
+	c.Events.calls = append(c.Events.calls, "finish")
+}
+
+func (c *HookController) GetHello() {
+	c.Events.calls = append(c.Events.calls, "hello")
+	c.ServeText(c.Greeter.Greet() + " " + c.French.Greet() + c.Suffix)
+}
+
+func (c *HookController) GetPanic() {
+	panic("boom")
+}
+
+func (c *HookController) ExcludeMethods() []string {
+	return nil
+}
+
+func TestControllerHooksAndInjection(t *testing.T) {
+	r, _ := newLoggedRouter(DefaultOptions())
+	events := &hookEvents{}
+	// 接口字段注入先注册的实现
+	r.Provide(english{}, &french{}, events)
+	r.ProvideNamed("suffix", "!")
+	r.AddAutoRouter(&HookController{})
+
+	tests := []struct {
+		path   string
+		auth   bool
+		status int
+		body   string
+		calls  string
+	}{
+		{"/api/hook/hello", true, http.StatusOK, "hello bonjour!", "prepare hello finish"},
+		{"/api/hook/hello", false, http.StatusUnauthorized, "login required", "prepare finish"},
+		{"/api/hook/panic", true, http.StatusInternalServerError, "", "prepare finish"},
+		// 钩子方法不注册为路由
+		{"/api/hook/prepare", true, http.StatusNotFound, "", ""},
+		{"/api/hook/finish", true, http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		events.calls = nil
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.auth {
+			req.Header.Set("Authorization", "token")
+		}
+		rw := httptest.NewRecorder()
+		r.ServeHTTP(rw, req)
+		if rw.Code != tt.status || !strings.Contains(rw.Body.String(), tt.body) {
+			t.Errorf("GET %s: status %d %q, want %d %q", tt.path, rw.Code, rw.Body, tt.status, tt.body)
+		}
+		if got := strings.Join(events.calls, " "); got != tt.calls {
+			t.Errorf("GET %s: calls %q, want %q", tt.path, got, tt.calls)
+		}
+	}
+}
+
+func TestInjectMissingService(t *testing.T) {
+	r, l := newLoggedRouter(DefaultOptions())
+	r.Provide(&hookEvents{})
+	r.AddAutoRouter(&HookController{})
+
+	rw := serve(r, http.MethodGet, "/api/hook/hello")
+	if rw.Code != http.StatusInternalServerError {
+		t.Errorf("status %d, want %d", rw.Code, http.StatusInternalServerError)
+	}
+	if len(l.entries) != 1 || !strings.Contains(l.entries[0], "no service of type") {
+		t.Errorf("log %q, want the missing service", l.entries)
+	}
+}
+
+type unexportedInjectController struct {
+	controller.Base
+	greeter greeter `inject:""`
+}
+
+func (c *unexportedInjectController) Hello() {}
+
+func TestProvidePanics(t *testing.T) {
+	r := newTestRouter()
+	r.ProvideNamed("a", 1)
+	tests := map[string]func(){
+		"nil service":      func() { r.Provide(nil) },
+		"empty name":       func() { r.ProvideNamed("", 1) },
+		"duplicate name":   func() { r.ProvideNamed("a", 2) },
+		"unexported field": func() { r.Get("/x", (*unexportedInjectController).Hello) },
+	}
+	for name, fn := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: want panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
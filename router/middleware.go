@@ -0,0 +1,83 @@
+package router
+
+import (
+	stdctx "context"
+	"fmt"
+	"letgo/context"
+	"net/http"
+)
+
+// Middleware 中间件，在next前后执行逻辑，不调用next即中止后续处理。
+//
+// 注册中间件的方法同时接受以下类型：
+// Middleware、func(HandlerFunc) HandlerFunc、func(http.Handler) http.Handler。
+// 执行顺序：全局中间件、分组中间件、路由中间件，同一级别按注册顺序执行。
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// toMiddleware 将支持的中间件类型转换为Middleware。
+func toMiddleware(m interface{}) Middleware {
+	switch mw := m.(type) {
+	case Middleware:
+		return mw
+	case func(HandlerFunc) HandlerFunc:
+		return mw
+	case func(http.Handler) http.Handler:
+		return fromHTTPMiddleware(mw)
+	}
+	panic(genError(fmt.Sprintf("unsupported middleware type %T", m)))
+}
+
+func toMiddlewares(ms []interface{}) []Middleware {
+	middlewares := make([]Middleware, 0, len(ms))
+	for _, m := range ms {
+		middlewares = append(middlewares, toMiddleware(m))
+	}
+	return middlewares
+}
+
+// httpContextKey 交给标准库形式的处理时，请求中保存Context的key。
+type httpContextKey struct{}
+
+// withContext 在请求的context.Context中保存ctx，用于从标准库形式的处理中取回Context。
+func withContext(req *http.Request, ctx context.Context) *http.Request {
+	return req.WithContext(stdctx.WithValue(req.Context(), httpContextKey{}, ctx))
+}
+
+// contextOf 取回withContext保存的Context。
+func contextOf(req *http.Request) (context.Context, bool) {
+	ctx, ok := req.Context().Value(httpContextKey{}).(context.Context)
+	return ctx, ok
+}
+
+// fromHTTPMiddleware 适配标准库形式的中间件，m在组装处理链时只调用一次，
+// 中间件在构造时保存的状态（如限流计数）在请求间共享。
+// Context通过请求的context.Context传给内层处理，中间件替换的ResponseWriter和Request
+// 在后续的处理中更新到Context中，返回后恢复，路径参数和保存的值保留。
+func fromHTTPMiddleware(m func(http.Handler) http.Handler) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		h := m(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			ctx, ok := contextOf(req)
+			if !ok {
+				panic(genError("http middleware dropped the request context"))
+			}
+			ctx.SetResponse(rw)
+			ctx.SetRequest(req)
+			next(ctx)
+		}))
+		return func(ctx context.Context) {
+			rw, req := ctx.Response(), ctx.Request()
+			h.ServeHTTP(rw, withContext(req, ctx))
+			// 外层中间件通过原来的ResponseWriter获取中间件实际写入的状态码
+			ctx.SetResponse(rw)
+			ctx.SetRequest(req)
+		}
+	}
+}
+
+// chain 用中间件包装处理函数，第一个中间件最先执行。
+func chain(h HandlerFunc, middlewares []Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
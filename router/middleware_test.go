@@ -0,0 +1,152 @@
+package router
+
+import (
+	stdctx "context"
+	"letgo/context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRouter() Router {
+	opts := DefaultOptions()
+	opts.DisableStatic = true
+	opts.DisableUpload = true
+	return NewRouterWithOptions(opts)
+}
+
+func serve(r Router, method, path string) *httptest.ResponseRecorder {
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, httptest.NewRequest(method, path, nil))
+	return rw
+}
+
+// trace 记录执行顺序的中间件，三种类型依次使用。
+func trace(calls *[]string, name string) []interface{} {
+	return []interface{}{
+		Middleware(func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context) {
+				*calls = append(*calls, name+".mw")
+				next(ctx)
+			}
+		}),
+		func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context) {
+				*calls = append(*calls, name+".func")
+				next(ctx)
+				*calls = append(*calls, name+".after")
+			}
+		},
+		func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				*calls = append(*calls, name+".http")
+				next.ServeHTTP(rw, req)
+			})
+		},
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	r := newTestRouter()
+	r.Use(trace(&calls, "global")...)
+	g := r.Group("/g", trace(&calls, "group")...)
+	g.Get("/x", func(ctx context.Context) {
+		calls = append(calls, "handler")
+	}, trace(&calls, "route")...)
+
+	serve(r, http.MethodGet, "/g/x")
+	want := "global.mw global.func global.http group.mw group.func group.http route.mw route.func route.http " +
+		"handler route.after group.after global.after"
+	if got := strings.Join(calls, " "); got != want {
+		t.Errorf("calls:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestMiddlewareAbort(t *testing.T) {
+	handled := false
+	r := newTestRouter()
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") == "" {
+				http.Error(rw, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+	r.Get("/private", func(ctx context.Context) { handled = true }, deny)
+
+	if rw := serve(r, http.MethodGet, "/private"); rw.Code != http.StatusUnauthorized || handled {
+		t.Errorf("status %d, handled %v, want %d and not handled", rw.Code, handled, http.StatusUnauthorized)
+	}
+}
+
+func TestHTTPMiddlewareState(t *testing.T) {
+	// 构造时创建状态的限流中间件，每个路由只构造一次，计数在请求间共享
+	constructed := 0
+	limit := func(next http.Handler) http.Handler {
+		constructed++
+		remaining := 2
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if remaining == 0 {
+				http.Error(rw, "too many requests", http.StatusTooManyRequests)
+				return
+			}
+			remaining--
+			next.ServeHTTP(rw, req)
+		})
+	}
+	r := newTestRouter()
+	r.Get("/api/item/:id", func(ctx context.Context) {
+		// 内层处理仍然可以获取路径参数和中间件设置的值
+		ctx.Response().Header().Set("X-Id", ctx.Param("id"))
+		ctx.Response().WriteHeader(http.StatusOK)
+	}, limit)
+
+	for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		rw := serve(r, http.MethodGet, "/api/item/7")
+		if rw.Code != want {
+			t.Errorf("request %d: status %d, want %d", i, rw.Code, want)
+		}
+		if want == http.StatusOK && rw.Header().Get("X-Id") != "7" {
+			t.Errorf("request %d: X-Id %q, want 7", i, rw.Header().Get("X-Id"))
+		}
+	}
+	if constructed != 1 {
+		t.Errorf("middleware constructed %d times, want 1", constructed)
+	}
+}
+
+func TestContextThroughMiddleware(t *testing.T) {
+	var (
+		user        string
+		hasDeadline bool
+	)
+	r := newTestRouter()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context) {
+			ctx.Set("user", "alice")
+			next(ctx)
+		}
+	})
+	// 标准库中间件设置的超时作用于之后的Context
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			c, cancel := stdctx.WithTimeout(req.Context(), time.Minute)
+			defer cancel()
+			next.ServeHTTP(rw, req.WithContext(c))
+		})
+	})
+	r.Get("/me", func(ctx context.Context) {
+		user, _ = ctx.Value("user").(string)
+		_, hasDeadline = ctx.Deadline()
+	})
+
+	serve(r, http.MethodGet, "/me")
+	if user != "alice" || !hasDeadline {
+		t.Errorf("user %q, deadline %v, want the value and the deadline set by middlewares", user, hasDeadline)
+	}
+}
@@ -0,0 +1,224 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"letgo/binding"
+	"letgo/context"
+	"letgo/openapi"
+	"letgo/validation"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// OpenAPI 根据已注册的路由生成OpenAPI文档。
+// 不限HTTP方法的路由，有参数时按POST生成，否则按GET生成。
+func (r *myRouter) OpenAPI() *openapi.Document {
+	doc := openapi.New(r.options.OpenAPITitle, r.options.OpenAPIVersion)
+	errorSchema := doc.SchemaOf(reflect.TypeOf(HTTPError{}))
+	operationIDs := make(map[string]bool)
+
+	for _, e := range r.entries {
+		route := e.route
+		if route.internal {
+			continue
+		}
+		method := e.method
+		if method == methodAny {
+			method = http.MethodGet
+			if route.methodInputType != nil {
+				method = http.MethodPost
+			}
+		}
+
+		docPath, params := openAPIPath(route.pattern)
+		op := &openapi.Operation{
+			Responses: make(map[string]*openapi.Response),
+		}
+		if len(route.tag) > 0 {
+			op.Tags = []string{route.tag}
+			op.Summary = route.tag + "." + route.methodName
+			doc.AddTag(route.tag)
+		}
+		op.OperationID = operationID(route, method, operationIDs)
+
+		if route.methodInputType != nil {
+			r.addInputDoc(doc, op, route.methodInputType, method, params)
+		}
+		op.Parameters = append(params, op.Parameters...)
+
+		if route.methodOutputType != nil {
+			schema := doc.SchemaOf(route.methodOutputType)
+			op.Responses["200"] = &openapi.Response{
+				Description: http.StatusText(http.StatusOK),
+				Content: map[string]*openapi.MediaType{
+					"application/json": {Schema: schema},
+					"application/xml":  {Schema: schema},
+				},
+			}
+		} else {
+			op.Responses["200"] = &openapi.Response{Description: http.StatusText(http.StatusOK)}
+		}
+		errorContentType := "application/json"
+		if r.options.ProblemJSON {
+			errorContentType = "application/problem+json"
+		}
+		op.Responses["default"] = &openapi.Response{
+			Description: "Error",
+			Content: map[string]*openapi.MediaType{
+				errorContentType: {Schema: errorSchema},
+			},
+		}
+
+		doc.AddOperation(docPath, strings.ToLower(method), op)
+	}
+	return doc
+}
+
+// addInputDoc 根据方法参数的结构体生成请求参数和请求体。
+func (r *myRouter) addInputDoc(doc *openapi.Document, op *openapi.Operation, it reflect.Type, method string, pathParams []*openapi.Parameter) {
+	for i := 0; i < it.NumField(); i++ {
+		sf := it.Field(i)
+		if len(sf.PkgPath) > 0 || !openapi.IsParamField(sf) {
+			continue
+		}
+		for _, in := range []string{binding.Tag_Query, binding.Tag_Path, binding.Tag_Header, binding.Tag_Cookie} {
+			name, ok := sf.Tag.Lookup(in)
+			if !ok || name == "-" {
+				continue
+			}
+			if name = strings.Split(name, ",")[0]; len(name) == 0 {
+				name = sf.Name
+			}
+			schema := doc.SchemaOf(sf.Type)
+			required := openapi.ApplyRules(schema, sf.Tag.Get(validation.Tag_Validate))
+
+			// 路由格式中的参数使用字段的类型
+			if in == binding.Tag_Path {
+				for _, p := range pathParams {
+					if p.Name == name {
+						p.Schema = schema
+					}
+				}
+				continue
+			}
+			op.Parameters = append(op.Parameters, &openapi.Parameter{
+				Name:     name,
+				In:       in,
+				Required: required,
+				Schema:   schema,
+			})
+		}
+	}
+
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return
+	}
+	body := &openapi.RequestBody{
+		Content: map[string]*openapi.MediaType{
+			"application/json": {Schema: doc.SchemaOf(it)},
+		},
+	}
+	if form := doc.FormSchemaOf(it); form != nil {
+		body.Content["multipart/form-data"] = &openapi.MediaType{Schema: form}
+		body.Content["application/x-www-form-urlencoded"] = &openapi.MediaType{Schema: form}
+	}
+	op.RequestBody = body
+}
+
+// openAPIPath 将路由格式转换为OpenAPI的路径，如 /api/user/:id:int 转换为 /api/user/{id}。
+func openAPIPath(pattern string) (string, []*openapi.Parameter) {
+	var params []*openapi.Parameter
+	segs := splitPath(pattern)
+	for i, seg := range segs {
+		switch seg[0] {
+		case ':':
+			name, rule, _, _ := parseParam(seg[1:])
+			schema := &openapi.Schema{Type: "string"}
+			if rule == "int" {
+				schema = &openapi.Schema{Type: "integer", Format: "int64"}
+			} else if strings.HasPrefix(rule, "(") {
+				schema.Pattern = "^" + rule + "$"
+			}
+			params = append(params, &openapi.Parameter{Name: name, In: "path", Required: true, Schema: schema})
+			segs[i] = "{" + name + "}"
+		case '*':
+			name := seg[1:]
+			params = append(params, &openapi.Parameter{Name: name, In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+			segs[i] = "{" + name + "}"
+		}
+	}
+	return "/" + strings.Join(segs, "/"), params
+}
+
+// operationID 生成不重复的操作ID，如 Account.Login。
+func operationID(route *route, method string, used map[string]bool) string {
+	id := route.tag + "." + route.methodName
+	if len(route.tag) == 0 {
+		id = strings.ToLower(method) + strings.NewReplacer("/", "_", ":", "", "*", "").Replace(route.pattern)
+	}
+	if used[id] {
+		id = fmt.Sprintf("%s.%s", id, strings.ToLower(method))
+	}
+	base := id
+	for i := 2; used[id]; i++ {
+		id = fmt.Sprintf("%s.%d", base, i)
+	}
+	used[id] = true
+	return id
+}
+
+// serveOpenAPI 注册文档及文档查看页面的路由。
+func (r *myRouter) serveOpenAPI() {
+	if len(r.options.OpenAPIPath) == 0 {
+		return
+	}
+	r.addInternalRoute(r.options.OpenAPIPath, func(ctx context.Context) {
+		rw := ctx.Response()
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(rw)
+		enc.SetIndent("", "  ")
+		enc.Encode(r.OpenAPI())
+	})
+
+	if len(r.options.OpenAPIUIPath) == 0 {
+		return
+	}
+	r.addInternalRoute(r.options.OpenAPIUIPath, func(ctx context.Context) {
+		ctx.Response().Header().Set("Content-Type", "text/html; charset=utf-8")
+		openAPIUITemplate.Execute(ctx.Response(), map[string]string{
+			"Title":  r.options.OpenAPITitle,
+			"Assets": strings.TrimSuffix(r.options.OpenAPIUIAssets, "/"),
+			"Spec":   r.options.OpenAPIPath,
+		})
+	})
+}
+
+// addInternalRoute 注册路由内部使用的GET路由，不包含在文档中。
+func (r *myRouter) addInternalRoute(pattern string, handler HandlerFunc) {
+	route := r.newHandlerRoute(pattern, handler)
+	route.internal = true
+	r.group.addRoute([]string{http.MethodGet}, route, nil)
+}
+
+var openAPIUITemplate = template.Must(template.New("openapi-ui").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Assets}}/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="{{.Assets}}/swagger-ui-bundle.js"></script>
+<script>
+window.onload = function() {
+	SwaggerUIBundle({url: "{{.Spec}}", dom_id: "#swagger-ui"});
+};
+</script>
+</body>
+</html>
+`))
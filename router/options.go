@@ -0,0 +1,257 @@
+package router
+
+import (
+	"fmt"
+	"letgo/config"
+	"letgo/imaging"
+	"letgo/log"
+	"letgo/storage"
+	"letgo/tus"
+	"letgo/view"
+	"path"
+	"strings"
+	"time"
+)
+
+// RouterOptions 路由配置。
+type RouterOptions struct {
+	StaticFolder     string // 静态资源目录
+	ProjectName      string // 项目目录，位于静态资源目录下
+	Homepage         string // 首页文件，位于项目目录下
+	PrefixAPI        string // 自动路由的前缀
+	PrefixStatic     string // 静态资源的路由前缀，映射到静态资源目录
+	PrefixUpload     string // 上传文件的路由前缀
+	SuffixController string // 控制器类型名的后缀，自动路由时去除
+
+	DisableStatic bool // 不提供静态资源
+	DisableUpload bool // 不提供上传文件
+
+	UploadMaxSize      int64    // 单个上传文件的最大字节数，见upload.Options
+	UploadMaxFiles     int      // 每次上传的最大文件数
+	UploadMaxFields    int      // 每次上传的最大非文件字段数
+	UploadMaxBodySize  int64    // 上传请求体的最大字节数，0时按UploadMaxSize和UploadMaxFiles计算
+	UploadAllowedExts  []string // 允许上传的扩展名，为空时不限制
+	UploadAllowedTypes []string // 允许上传的MIME类型，按文件内容检测，为空时不限制
+	UploadNaming       string   // 文件的命名方式，见upload.Naming_Hash
+	UploadDateShard    string   // 按上传日期分目录的时间格式，如 2006/01/02，为空时不分目录
+	UploadIndexFolder  string   // 保存内容索引的目录，不能位于静态资源目录下，默认为空，不使用索引
+	// UploadImageVariants 上传图片后生成的变体，如缩略图，见upload.Options.Variants
+	UploadImageVariants []imaging.Variant
+	// UploadStripMetadata 上传的JPEG、PNG图片重新编码后保存，去除EXIF等元数据，见upload.Options.StripMetadata
+	UploadStripMetadata bool
+	// UploadStorage 保存上传文件的存储，为空时保存到静态资源目录下的上传目录
+	UploadStorage storage.Storage
+
+	EnableTus     bool          // 提供可续传上传，默认不提供，DisableUpload时也不提供
+	PrefixTus     string        // 可续传上传（tus协议）的路由前缀，完成的文件按Upload*的配置检查后保存到UploadStorage
+	TusFolder     string        // 保存上传中的文件的目录
+	TusMaxSize    int64         // 可续传上传的最大字节数，0时使用UploadMaxSize，小于0时不限制
+	TusExpiration time.Duration // 未完成的上传在最后一次写入后的有效期，过期后定时清理
+	// TusOnComplete 可续传上传完成并保存后调用，如记录文件的归属，客户端从完成上传的响应中获取文件
+	TusOnComplete func(upload *tus.Upload)
+
+	OpenAPIPath     string // OpenAPI文档的路由，为空时不提供
+	OpenAPIUIPath   string // 文档查看页面的路由，为空时不提供
+	OpenAPIUIAssets string // 文档查看页面的swagger-ui资源地址，默认为静态资源路由下的swagger-ui目录
+	OpenAPITitle    string // 文档标题
+	OpenAPIVersion  string // 文档中的API版本
+
+	RoutesPath string // 查看路由表的调试路由，为空时不提供
+
+	ViewsFolder    string // 模板根目录，见view.Options
+	ViewsExtension string // 模板文件的扩展名
+	ViewsLayout    string // 默认布局，为空时不使用布局
+	ViewsDevMode   bool   // 模板文件修改后自动重新解析，包括首页
+
+	ProblemJSON   bool          // 错误响应使用application/problem+json
+	ErrorRenderer ErrorRenderer // 自定义错误响应，为空时输出HTTPError的json
+	Logger        log.Logger    // 路由使用的日志，为空时使用log.Log
+}
+
+// 配置文件中路由配置的key。
+const (
+	ConfigKey_StaticFolder        = "router_static_folder"
+	ConfigKey_ProjectName         = "router_project_name"
+	ConfigKey_Homepage            = "router_homepage"
+	ConfigKey_PrefixAPI           = "router_prefix_api"
+	ConfigKey_PrefixStatic        = "router_prefix_static"
+	ConfigKey_PrefixUpload        = "router_prefix_upload"
+	ConfigKey_SuffixController    = "router_suffix_controller"
+	ConfigKey_DisableStatic       = "router_disable_static"
+	ConfigKey_DisableUpload       = "router_disable_upload"
+	ConfigKey_UploadMaxSize       = "router_upload_max_size"
+	ConfigKey_UploadMaxFiles      = "router_upload_max_files"
+	ConfigKey_UploadMaxFields     = "router_upload_max_fields"
+	ConfigKey_UploadMaxBodySize   = "router_upload_max_body_size"
+	ConfigKey_UploadAllowedExts   = "router_upload_allowed_exts"  // 逗号分隔，如 .jpg,.png
+	ConfigKey_UploadAllowedTypes  = "router_upload_allowed_types" // 逗号分隔，如 image/*,application/pdf
+	ConfigKey_UploadNaming        = "router_upload_naming"        // hash或random
+	ConfigKey_UploadDateShard     = "router_upload_date_shard"    // 如 2006/01/02
+	ConfigKey_UploadIndexFolder   = "router_upload_index_folder"
+	ConfigKey_UploadImageVariants = "router_upload_image_variants" // 如 thumb:200x200:fill,medium:800x0，见imaging.ParseVariants
+	ConfigKey_UploadStripMetadata = "router_upload_strip_metadata"
+	ConfigKey_UploadStorage       = "router_upload_storage"        // 存储类型，见storage.AdapterName_Local等
+	ConfigKey_UploadStorageConfig = "router_upload_storage_config" // json格式的存储配置
+	ConfigKey_EnableTus           = "router_enable_tus"
+	ConfigKey_PrefixTus           = "router_prefix_tus"
+	ConfigKey_TusFolder           = "router_tus_folder"
+	ConfigKey_TusMaxSize          = "router_tus_max_size"
+	ConfigKey_TusExpiration       = "router_tus_expiration" // 如 24h
+	ConfigKey_ProblemJSON         = "router_problem_json"
+	ConfigKey_OpenAPIPath         = "router_openapi_path"
+	ConfigKey_OpenAPIUIPath       = "router_openapi_ui_path"
+	ConfigKey_OpenAPIUIAssets     = "router_openapi_ui_assets"
+	ConfigKey_OpenAPITitle        = "router_openapi_title"
+	ConfigKey_OpenAPIVersion      = "router_openapi_version"
+	ConfigKey_RoutesPath          = "router_routes_path"
+	ConfigKey_ViewsFolder         = "router_views_folder"
+	ConfigKey_ViewsExtension      = "router_views_extension"
+	ConfigKey_ViewsLayout         = "router_views_layout"
+	ConfigKey_ViewsDevMode        = "router_views_dev_mode"
+)
+
+// DefaultOptions 默认的路由配置。
+func DefaultOptions() RouterOptions {
+	return RouterOptions{
+		StaticFolder:     Static_Folder,
+		ProjectName:      Project_Name,
+		Homepage:         Homepage,
+		PrefixAPI:        Prefix_API,
+		PrefixStatic:     Prefix_Static,
+		PrefixUpload:     Prefix_Upload,
+		PrefixTus:        Prefix_Tus,
+		TusFolder:        Tus_Folder,
+		SuffixController: Suffix_Controller,
+		OpenAPIUIAssets:  path.Join(Prefix_Static, OpenAPI_UIAssets),
+		OpenAPITitle:     Project_Name,
+		OpenAPIVersion:   "1.0.0",
+		ViewsFolder:      Views_Folder,
+		ViewsExtension:   view.Extension,
+	}
+}
+
+// OptionsFromConfig 读取配置文件中的路由配置，未配置的项使用默认值。
+func OptionsFromConfig(conf config.Configer) (RouterOptions, error) {
+	opts := DefaultOptions()
+
+	strs := map[string]*string{
+		ConfigKey_StaticFolder:      &opts.StaticFolder,
+		ConfigKey_ProjectName:       &opts.ProjectName,
+		ConfigKey_Homepage:          &opts.Homepage,
+		ConfigKey_PrefixAPI:         &opts.PrefixAPI,
+		ConfigKey_PrefixStatic:      &opts.PrefixStatic,
+		ConfigKey_PrefixUpload:      &opts.PrefixUpload,
+		ConfigKey_PrefixTus:         &opts.PrefixTus,
+		ConfigKey_TusFolder:         &opts.TusFolder,
+		ConfigKey_UploadNaming:      &opts.UploadNaming,
+		ConfigKey_UploadDateShard:   &opts.UploadDateShard,
+		ConfigKey_UploadIndexFolder: &opts.UploadIndexFolder,
+		ConfigKey_SuffixController:  &opts.SuffixController,
+		ConfigKey_OpenAPIPath:       &opts.OpenAPIPath,
+		ConfigKey_OpenAPIUIPath:     &opts.OpenAPIUIPath,
+		ConfigKey_OpenAPIUIAssets:   &opts.OpenAPIUIAssets,
+		ConfigKey_OpenAPITitle:      &opts.OpenAPITitle,
+		ConfigKey_OpenAPIVersion:    &opts.OpenAPIVersion,
+		ConfigKey_RoutesPath:        &opts.RoutesPath,
+		ConfigKey_ViewsFolder:       &opts.ViewsFolder,
+		ConfigKey_ViewsExtension:    &opts.ViewsExtension,
+		ConfigKey_ViewsLayout:       &opts.ViewsLayout,
+	}
+	for key, val := range strs {
+		if v := conf.Get(key); len(v) > 0 {
+			*val = v
+		}
+	}
+	if len(conf.Get(ConfigKey_OpenAPIUIAssets)) == 0 {
+		opts.OpenAPIUIAssets = path.Join(opts.PrefixStatic, OpenAPI_UIAssets)
+	}
+
+	bools := map[string]*bool{
+		ConfigKey_DisableStatic:       &opts.DisableStatic,
+		ConfigKey_DisableUpload:       &opts.DisableUpload,
+		ConfigKey_EnableTus:           &opts.EnableTus,
+		ConfigKey_UploadStripMetadata: &opts.UploadStripMetadata,
+		ConfigKey_ProblemJSON:         &opts.ProblemJSON,
+		ConfigKey_ViewsDevMode:        &opts.ViewsDevMode,
+	}
+	for key, val := range bools {
+		if len(conf.Get(key)) == 0 {
+			continue
+		}
+		b, err := conf.Bool(key)
+		if err != nil {
+			return opts, genError(fmt.Sprintf("config %s: %v", key, err))
+		}
+		*val = b
+	}
+
+	if len(conf.Get(ConfigKey_UploadMaxSize)) > 0 {
+		n, err := conf.Int64(ConfigKey_UploadMaxSize)
+		if err != nil {
+			return opts, genError(fmt.Sprintf("config %s: %v", ConfigKey_UploadMaxSize, err))
+		}
+		opts.UploadMaxSize = n
+	}
+	if len(conf.Get(ConfigKey_UploadMaxFiles)) > 0 {
+		n, err := conf.Int(ConfigKey_UploadMaxFiles)
+		if err != nil {
+			return opts, genError(fmt.Sprintf("config %s: %v", ConfigKey_UploadMaxFiles, err))
+		}
+		opts.UploadMaxFiles = n
+	}
+	if len(conf.Get(ConfigKey_UploadMaxFields)) > 0 {
+		n, err := conf.Int(ConfigKey_UploadMaxFields)
+		if err != nil {
+			return opts, genError(fmt.Sprintf("config %s: %v", ConfigKey_UploadMaxFields, err))
+		}
+		opts.UploadMaxFields = n
+	}
+	if len(conf.Get(ConfigKey_UploadMaxBodySize)) > 0 {
+		n, err := conf.Int64(ConfigKey_UploadMaxBodySize)
+		if err != nil {
+			return opts, genError(fmt.Sprintf("config %s: %v", ConfigKey_UploadMaxBodySize, err))
+		}
+		opts.UploadMaxBodySize = n
+	}
+	if len(conf.Get(ConfigKey_TusMaxSize)) > 0 {
+		n, err := conf.Int64(ConfigKey_TusMaxSize)
+		if err != nil {
+			return opts, genError(fmt.Sprintf("config %s: %v", ConfigKey_TusMaxSize, err))
+		}
+		opts.TusMaxSize = n
+	}
+	if v := conf.Get(ConfigKey_TusExpiration); len(v) > 0 {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return opts, genError(fmt.Sprintf("config %s: %v", ConfigKey_TusExpiration, err))
+		}
+		opts.TusExpiration = d
+	}
+	opts.UploadAllowedExts = splitList(conf.Get(ConfigKey_UploadAllowedExts))
+	opts.UploadAllowedTypes = splitList(conf.Get(ConfigKey_UploadAllowedTypes))
+	variants, err := imaging.ParseVariants(conf.Get(ConfigKey_UploadImageVariants))
+	if err != nil {
+		return opts, genError(fmt.Sprintf("config %s: %v", ConfigKey_UploadImageVariants, err))
+	}
+	opts.UploadImageVariants = variants
+	if adapter := conf.Get(ConfigKey_UploadStorage); len(adapter) > 0 {
+		s, err := storage.New(adapter, conf.Get(ConfigKey_UploadStorageConfig))
+		if err != nil {
+			return opts, genError(fmt.Sprintf("config %s: %v", ConfigKey_UploadStorage, err))
+		}
+		opts.UploadStorage = s
+	}
+
+	return opts, nil
+}
+
+// splitList 拆分逗号分隔的配置项，忽略空项。
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			list = append(list, item)
+		}
+	}
+	return list
+}
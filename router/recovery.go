@@ -0,0 +1,38 @@
+package router
+
+import (
+	"fmt"
+	"letgo/context"
+	"letgo/log"
+	"net/http"
+	"runtime/debug"
+)
+
+// recovery 捕获处理请求时的panic，记录日志和调用栈，并返回500错误。
+func (r *myRouter) recovery(ctx context.Context) {
+	err := recover()
+	if err == nil {
+		return
+	}
+	// 客户端断开等情况由标准库处理
+	if err == http.ErrAbortHandler {
+		panic(err)
+	}
+
+	req := ctx.Request()
+	if l := r.logger(); l != nil {
+		l.Crash("panic in %s %s: %v\n%s", req.Method, req.URL.Path, err, debug.Stack())
+	}
+
+	e := NewHTTPError(http.StatusInternalServerError, "")
+	e.Err = fmt.Errorf("panic: %v", err)
+	r.writeError(ctx, e)
+}
+
+// logger 路由使用的日志，未配置时使用log.Log。
+func (r *myRouter) logger() log.Logger {
+	if r.options.Logger != nil {
+		return r.options.Logger
+	}
+	return log.Log
+}
@@ -0,0 +1,137 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"letgo/context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// recordLogger 记录日志的级别和内容。
+type recordLogger struct {
+	entries []string
+}
+
+func (l *recordLogger) add(level string, f interface{}, args ...interface{}) {
+	l.entries = append(l.entries, level+" "+fmt.Sprintf(fmt.Sprint(f), args...))
+}
+
+func (l *recordLogger) Init(jsonConfig string) error              { return nil }
+func (l *recordLogger) Trace(f interface{}, args ...interface{})  { l.add("trace", f, args...) }
+func (l *recordLogger) Debug(f interface{}, args ...interface{})  { l.add("debug", f, args...) }
+func (l *recordLogger) Info(f interface{}, args ...interface{})   { l.add("info", f, args...) }
+func (l *recordLogger) Status(f interface{}, args ...interface{}) { l.add("status", f, args...) }
+func (l *recordLogger) Notice(f interface{}, args ...interface{}) { l.add("notice", f, args...) }
+func (l *recordLogger) Warn(f interface{}, args ...interface{})   { l.add("warn", f, args...) }
+func (l *recordLogger) Error(f interface{}, args ...interface{})  { l.add("error", f, args...) }
+func (l *recordLogger) Fatal(f interface{}, args ...interface{})  { l.add("fatal", f, args...) }
+func (l *recordLogger) Crash(f interface{}, args ...interface{})  { l.add("crash", f, args...) }
+
+func newLoggedRouter(opts RouterOptions) (Router, *recordLogger) {
+	l := &recordLogger{}
+	opts.DisableStatic = true
+	opts.DisableUpload = true
+	opts.Logger = l
+	return NewRouterWithOptions(opts), l
+}
+
+func TestRecovery(t *testing.T) {
+	r, l := newLoggedRouter(DefaultOptions())
+	r.Get("/panic", func(ctx context.Context) { panic("secret state") })
+	r.Get("/partial", func(ctx context.Context) {
+		ctx.Response().Write([]byte("partial"))
+		panic("after write")
+	})
+	r.Get("/abort", func(ctx context.Context) { panic(http.ErrAbortHandler) })
+
+	rw := serve(r, http.MethodGet, "/panic")
+	var e HTTPError
+	if err := json.Unmarshal(rw.Body.Bytes(), &e); rw.Code != http.StatusInternalServerError || err != nil || e.Status != http.StatusInternalServerError {
+		t.Errorf("GET /panic: status %d, body %q", rw.Code, rw.Body)
+	}
+	if ct := rw.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") || rw.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Errorf("GET /panic: headers %v", rw.Header())
+	}
+	if strings.Contains(rw.Body.String(), "secret") {
+		t.Errorf("GET /panic: body %q leaks the panic value", rw.Body)
+	}
+	if len(l.entries) != 1 || !strings.HasPrefix(l.entries[0], "crash panic in GET /panic: secret state") {
+		t.Errorf("log %q, want the panic with its stack", l.entries)
+	}
+
+	// 已写入响应时不再输出错误
+	if rw := serve(r, http.MethodGet, "/partial"); rw.Code != http.StatusOK || rw.Body.String() != "partial" {
+		t.Errorf("GET /partial: status %d, body %q", rw.Code, rw.Body)
+	}
+
+	defer func() {
+		if err := recover(); err != http.ErrAbortHandler {
+			t.Errorf("GET /abort: recovered %v, want http.ErrAbortHandler", err)
+		}
+	}()
+	serve(r, http.MethodGet, "/abort")
+}
+
+func TestErrorResponses(t *testing.T) {
+	fail := func(err error) HandlerFunc {
+		return func(ctx context.Context) { panic(err) }
+	}
+	opts := DefaultOptions()
+	opts.ProblemJSON = true
+	r, _ := newLoggedRouter(opts)
+	r.Get("/fail", fail(errors.New("x")))
+	rw := serve(r, http.MethodGet, "/fail")
+	if ct := rw.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/problem+json") {
+		t.Errorf("ProblemJSON: Content-Type %q", ct)
+	}
+
+	var rendered error
+	opts = DefaultOptions()
+	opts.ErrorRenderer = func(ctx context.Context, err error) {
+		rendered = err
+		ctx.Response().WriteHeader(http.StatusTeapot)
+	}
+	r, _ = newLoggedRouter(opts)
+	if rw := serve(r, http.MethodGet, "/missing"); rw.Code != http.StatusTeapot || ToHTTPError(rendered).Status != http.StatusNotFound {
+		t.Errorf("ErrorRenderer: status %d, rendered %v", rw.Code, rendered)
+	}
+}
+
+func TestToHTTPError(t *testing.T) {
+	notFound := NewHTTPError(http.StatusNotFound, "no user")
+	tests := []struct {
+		err    error
+		status int
+		detail string
+	}{
+		{notFound, http.StatusNotFound, "no user"},
+		{fmt.Errorf("lookup: %w", notFound), http.StatusNotFound, "no user"},
+		{teapotError{}, http.StatusTeapot, "short and stout"},
+		{fmt.Errorf("brew: %w", teapotError{}), http.StatusTeapot, "brew: short and stout"},
+		{errors.New("internal"), http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		e := ToHTTPError(tt.err)
+		if e.Status != tt.status || e.Detail != tt.detail || e.Title != http.StatusText(tt.status) {
+			t.Errorf("ToHTTPError(%v) = %+v, want status %d detail %q", tt.err, e, tt.status, tt.detail)
+		}
+	}
+}
+
+func TestRenderErrorLogging(t *testing.T) {
+	r, l := newLoggedRouter(DefaultOptions())
+	r.Get("/bad", (*ResultController).Hello)
+	r.Get("/fail", (*ResultController).Fail)
+
+	serve(r, http.MethodGet, "/bad")
+	if len(l.entries) != 0 {
+		t.Errorf("4xx logged: %q", l.entries)
+	}
+	serve(r, http.MethodGet, "/fail")
+	if len(l.entries) != 1 || !strings.Contains(l.entries[0], "database is down") {
+		t.Errorf("5xx log %q, want the internal error", l.entries)
+	}
+}
@@ -0,0 +1,68 @@
+package router
+
+import (
+	"letgo/context"
+	"letgo/render"
+	"net/http"
+	"reflect"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// handleResults 处理控制器方法的返回值：最后一个返回值为error且不为nil时输出错误响应，
+// 否则将第一个返回值按Accept请求头序列化后输出。
+func (r *myRouter) handleResults(ctx context.Context, outs []reflect.Value) {
+	if n := len(outs); n > 0 && outs[n-1].Type().Implements(errorType) {
+		if !isNil(outs[n-1]) {
+			r.renderError(ctx, outs[n-1].Interface().(error))
+			return
+		}
+		outs = outs[:n-1]
+	}
+	if len(outs) == 0 {
+		return
+	}
+
+	if err := r.serveResult(ctx, outs[0].Interface()); err != nil {
+		r.renderError(ctx, err)
+	}
+}
+
+// serveResult 按Accept请求头选择编码器输出数据，默认json，见render.Negotiate。
+// 选择的编码器无法序列化数据时（如xml不支持map）使用json，
+// 序列化失败时不写入响应，由调用方输出错误。
+// 方法已自行输出响应（如调用了ServeText）时忽略返回值。
+func (r *myRouter) serveResult(ctx context.Context, data interface{}) error {
+	rw := ctx.Response()
+	if rw.Written() {
+		return nil
+	}
+	enc := render.Negotiate(ctx.Request().Header.Get("Accept"))
+	body, err := enc.Marshal(data)
+	if err != nil && enc.MediaType != render.Default().MediaType {
+		enc = render.Default()
+		body, err = enc.Marshal(data)
+	}
+	if err != nil {
+		return err
+	}
+
+	rw.Header().Set("Content-Type", enc.Type())
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(body); err != nil {
+		// 响应头已写入，无法再输出错误，只记录日志
+		if l := r.logger(); l != nil {
+			req := ctx.Request()
+			l.Warn("%s %s: write response: %v", req.Method, req.URL.Path, err)
+		}
+	}
+	return nil
+}
+
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package router
+
+import (
+	"encoding/json"
+	"errors"
+	"letgo/binding"
+	"letgo/controller"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type resultIn struct {
+	Name string `query:"name"`
+}
+
+type resultOut struct {
+	Greeting string `json:"greeting" xml:"greeting"`
+}
+
+// teapotError 带状态码的自定义错误。
+type teapotError struct{}
+
+func (teapotError) Error() string   { return "short and stout" }
+func (teapotError) StatusCode() int { return http.StatusTeapot }
+
+type ResultController struct {
+	controller.Base
+}
+
+func (c *ResultController) Ping() error {
+	return nil
+}
+
+func (c *ResultController) Fail() error {
+	return errors.New("database is down")
+}
+
+func (c *ResultController) Hello(in resultIn) (resultOut, error) {
+	switch in.Name {
+	case "":
+		return resultOut{}, NewHTTPError(http.StatusNotFound, "no name")
+	case "teapot":
+		return resultOut{}, teapotError{}
+	}
+	return resultOut{Greeting: "hello " + in.Name}, nil
+}
+
+func (c *ResultController) Custom() (resultOut, error) {
+	c.ServeText("custom")
+	return resultOut{Greeting: "ignored"}, nil
+}
+
+func TestHandleResults(t *testing.T) {
+	r := newTestRouter()
+	r.Get("/ping", (*ResultController).Ping)
+	r.Get("/fail", (*ResultController).Fail)
+	r.Get("/hello", (*ResultController).Hello)
+	r.Get("/custom", (*ResultController).Custom)
+
+	tests := []struct {
+		path   string
+		accept string
+		status int
+		body   string // 响应体包含的内容
+	}{
+		{"/ping", "", http.StatusOK, ""},
+		{"/fail", "", http.StatusInternalServerError, `"status":500`},
+		{"/hello?name=go", "", http.StatusOK, `{"greeting":"hello go"}`},
+		{"/hello?name=go", "application/xml", http.StatusOK, "<greeting>hello go</greeting>"},
+		{"/hello", "", http.StatusNotFound, `"detail":"no name"`},
+		{"/hello?name=teapot", "", http.StatusTeapot, `"detail":"short and stout"`},
+		{"/custom", "", http.StatusOK, "custom"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if len(tt.accept) > 0 {
+			req.Header.Set("Accept", tt.accept)
+		}
+		rw := httptest.NewRecorder()
+		r.ServeHTTP(rw, req)
+		body := rw.Body.String()
+		if rw.Code != tt.status || !strings.Contains(body, tt.body) {
+			t.Errorf("GET %s: status %d %q, want %d containing %q", tt.path, rw.Code, body, tt.status, tt.body)
+		}
+		if tt.status >= 400 {
+			var e HTTPError
+			if err := json.Unmarshal(rw.Body.Bytes(), &e); err != nil || e.Status != tt.status {
+				t.Errorf("GET %s: error body %q: %v", tt.path, body, err)
+			}
+		}
+	}
+
+	// 内部错误不返回给客户端
+	if rw := serve(r, http.MethodGet, "/fail"); strings.Contains(rw.Body.String(), "database") {
+		t.Errorf("GET /fail: body %q leaks the internal error", rw.Body)
+	}
+	// 已输出的响应不会追加返回值
+	if rw := serve(r, http.MethodGet, "/custom"); rw.Body.String() != "custom" {
+		t.Errorf("GET /custom: body %q, want %q", rw.Body, "custom")
+	}
+}
+
+func TestBindBodyTooLarge(t *testing.T) {
+	defer func(size int64) { binding.MaxBodySize = size }(binding.MaxBodySize)
+	binding.MaxBodySize = 8
+
+	r := newTestRouter()
+	r.Post("/hello", (*ResultController).Hello)
+	req := httptest.NewRequest(http.MethodPost, "/hello?name=go", strings.NewReader(`{"padding":"0123456789"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+	if rw.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status %d, want %d: %s", rw.Code, http.StatusRequestEntityTooLarge, rw.Body)
+	}
+}
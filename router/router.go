@@ -1,61 +1,204 @@
 package router
 
 import (
-	"crypto/md5"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
-	"io"
-	"io/ioutil"
+	"letgo/binding"
 	"letgo/context"
 	"letgo/controller"
+	"letgo/openapi"
 	"letgo/plugins/cors"
+	"letgo/render"
+	"letgo/storage"
+	"letgo/tus"
+	"letgo/upload"
+	"letgo/validation"
+	"letgo/view"
 	"net/http"
 	"os"
 	"path"
 	"reflect"
-	"strconv"
 	"strings"
 	"sync"
-	"time"
+	"unicode"
 )
 
+// Router 路由，Router上的Use添加全局中间件，作用于所有请求。
 type Router interface {
+	Group
 	ServeHTTP(rw http.ResponseWriter, req *http.Request)
-	AddAutoRouter(c controller.Controller)
+
+	// OpenAPI 根据已注册的路由生成OpenAPI文档。
+	OpenAPI() *openapi.Document
+	// Routes 按注册顺序返回已注册的路由。
+	Routes() []RouteInfo
+	// Errors 注册路由时发现的冲突，如路由格式和HTTP方法都相同、参数无法区分，
+	// 冲突的路由未被注册。启动时检查，或使用MustValid。
+	Errors() []error
+	// MustValid 注册路由有冲突时panic，用于注册完全部路由后检查。
+	MustValid()
+
+	// URLFor 生成命名路由的URL，params为成对的参数名和值，
+	// 路由格式中的参数填入路径，其余的作为查询参数，如
+	// URLFor("User.Orders", "id", 42, "page", 2) 生成 /api/user/42/orders?page=2。
+	// 参数值中的 / 转义为 %2F，匹配路由时反转义，通配参数中的 / 保留为路径分隔符。
+	URLFor(name string, params ...interface{}) (string, error)
+	// FuncMap 模板中可以使用的函数，包括urlfor。
+	FuncMap() template.FuncMap
+	// View 控制器使用的模板渲染，见RouterOptions.ViewsFolder。
+	View() view.View
+	// Uploader 处理上传路由的上传，配置UploadIndexFolder时可用于按内容查找已上传的文件。
+	Uploader() upload.Uploader
+
+	// Close 停止路由启动的后台任务，如定时清理过期的可续传上传。
+	Close()
+
+	// Provide 注册按类型注入到控制器字段的服务，如数据库连接、日志。
+	Provide(services ...interface{})
+	// ProvideNamed 注册按名称注入到控制器字段的服务。
+	ProvideNamed(name string, service interface{})
 }
 
 type myRouter struct {
-	routerMap    map[string]route
-	staticFolder string
-	project      string
-	homepage     string
+	group
+
+	tree    *node             // 路由前缀树
+	entries []entry           // 按注册顺序保存的路由
+	errs    []error           // 注册路由时发现的冲突
+	names   map[string]*route // 命名的路由，用于生成URL
+	options RouterOptions
 
 	pool sync.Pool
 
 	cors cors.CORS // 跨域访问
+
+	middlewares []Middleware // 全局中间件
+	serve       HandlerFunc  // 经全局中间件包装后的分发函数
+
+	services      []reflect.Value          // 按类型注入的服务
+	namedServices map[string]reflect.Value // 按名称注入的服务
+
+	views view.View // 控制器使用的模板
+	pages view.View // 项目目录下的页面，如首页
+
+	uploader upload.Uploader // 处理上传路由前缀下的上传
+	tus      tus.Handler     // 处理可续传上传路由前缀下的上传
+	stopTus  func()          // 停止定时清理过期的可续传上传
 }
 
 type route struct {
-	pattern         string // 路由格式：/api/account/login
-	controllerType  reflect.Type
-	methodName      string
-	methodInputType reflect.Type // 方法参数类型，转发路由时转换json数据
+	pattern          string // 路由格式：/api/account/login、/api/user/:id:int
+	controllerType   reflect.Type
+	methodName       string
+	method           reflect.Value // 控制器方法，第一个参数为控制器
+	methodInputType  reflect.Type  // 方法参数类型，转发路由时转换json数据
+	methodOutputType reflect.Type  // 方法返回值类型，用于生成文档
+	tag              string        // 控制器名，用于生成文档
+	name             string        // 路由名称，用于生成URL
+	injects          []injectField // 需要注入服务的控制器字段
+
+	handler  HandlerFunc
+	internal bool // 路由内部使用的路由，如文档，不包含在文档中
 }
 
+// entry 注册的路由及其HTTP方法。
+type entry struct {
+	method string
+	route  *route
+}
+
+// NewRouter 使用默认配置创建路由。
 func NewRouter() Router {
+	return NewRouterWithOptions(DefaultOptions())
+}
+
+// NewRouterWithOptions 使用指定配置创建路由。
+func NewRouterWithOptions(opts RouterOptions) Router {
 	r := &myRouter{
-		routerMap: make(map[string]route),
+		tree:    newNode(),
+		names:   make(map[string]*route),
+		options: opts,
+
+		namedServices: make(map[string]reflect.Value),
+	}
+	r.group = group{router: r, autoPrefix: opts.PrefixAPI}
+	r.views = view.New(view.Options{
+		Root:      opts.ViewsFolder,
+		Extension: opts.ViewsExtension,
+		Layout:    opts.ViewsLayout,
+		DevMode:   opts.ViewsDevMode,
+		Funcs:     r.FuncMap(),
+	})
+	r.pages = view.New(view.Options{
+		Root:      path.Join(opts.StaticFolder, opts.ProjectName),
+		Extension: path.Ext(opts.Homepage),
+		DevMode:   opts.ViewsDevMode,
+		Funcs:     r.FuncMap(),
+	})
+	r.serveOpenAPI()
+	r.serveRoutes()
+	if opts.UploadStorage == nil {
+		opts.UploadStorage = storage.NewLocal(path.Join(opts.StaticFolder, opts.PrefixUpload), path.Join(opts.PrefixStatic, opts.PrefixUpload))
+	}
+	var index storage.Storage
+	if len(opts.UploadIndexFolder) > 0 {
+		index = storage.NewLocal(opts.UploadIndexFolder, "")
+	}
+	uploadOpts := upload.Options{
+		Storage:       opts.UploadStorage,
+		MaxSize:       opts.UploadMaxSize,
+		MaxFiles:      opts.UploadMaxFiles,
+		MaxFields:     opts.UploadMaxFields,
+		MaxBodySize:   opts.UploadMaxBodySize,
+		AllowedExts:   opts.UploadAllowedExts,
+		AllowedTypes:  opts.UploadAllowedTypes,
+		Naming:        opts.UploadNaming,
+		DateShard:     opts.UploadDateShard,
+		Index:         index,
+		Variants:      opts.UploadImageVariants,
+		StripMetadata: opts.UploadStripMetadata,
+	}
+	r.uploader = upload.New(uploadOpts)
+	if opts.EnableTus && !opts.DisableUpload {
+		// 完成的文件与上传路由做相同的检查，大小未配置时与上传路由的限制相同
+		tusUploader := r.uploader
+		maxSize := opts.TusMaxSize
+		if maxSize == 0 {
+			maxSize = opts.UploadMaxSize
+		}
+		if maxSize == 0 {
+			maxSize = upload.Max_Size
+		}
+		if opts.TusMaxSize != 0 {
+			uploadOpts.MaxSize = opts.TusMaxSize
+			tusUploader = upload.New(uploadOpts)
+		}
+		r.tus = tus.New(tus.Options{
+			BasePath:   opts.PrefixTus,
+			Folder:     opts.TusFolder,
+			Uploader:   tusUploader,
+			MaxSize:    maxSize,
+			Expiration: opts.TusExpiration,
+			Logger:     opts.Logger,
+			OnComplete: opts.TusOnComplete,
+			// 与上传路由的错误使用相同的格式
+			ErrorHandler: func(rw http.ResponseWriter, req *http.Request, err tus.StatusError) {
+				if ctx, ok := contextOf(req); ok {
+					r.writeError(ctx, err)
+					return
+				}
+				http.Error(rw, err.Error(), err.StatusCode())
+			},
+		})
+		r.stopTus = r.tus.StartCleanup(tus.Default_CleanupInterval)
 	}
 	r.pool.New = func() interface{} {
 		return context.New()
 	}
 
 	r.cors = cors.New()
-	r.staticFolder = Static_Folder
-	r.project = Project_Name
-	r.homepage = Homepage
+	r.serve = r.dispatch
 
 	return r
 }
@@ -64,249 +207,288 @@ func (r *myRouter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	ctx := r.pool.Get().(context.Context)
 	defer r.pool.Put(ctx)
 	ctx.Reset(rw, req)
+	defer r.recovery(ctx)
 
 	// 判断是否支持跨域访问
 	if r.cors != nil {
 		r.cors.PrepareCors(ctx.Response(), ctx.Request())
+		// 预检请求已由跨域处理写入响应
+		if ctx.Response().Written() {
+			return
+		}
 	}
 
+	r.serve(ctx)
+}
+
+func (r *myRouter) Use(middlewares ...interface{}) {
+	r.middlewares = append(r.middlewares, toMiddlewares(middlewares)...)
+	r.serve = chain(r.dispatch, r.middlewares)
+}
+
+// dispatch 按URL分发请求。
+func (r *myRouter) dispatch(ctx context.Context) {
+	rw, req := ctx.Response(), ctx.Request()
+
 	if req.URL.Path == "/" {
 		// 默认首页
-		r.serveHtml(rw, req, path.Join(r.staticFolder, r.project, r.homepage))
-	} else if strings.HasPrefix(req.URL.Path, Prefix_API) {
-		// 处理API访问逻辑
-		r.serveAPI(rw, req, ctx)
-	} else if strings.HasPrefix(req.URL.Path, Prefix_Static) {
+		r.serveHtml(ctx, strings.TrimSuffix(r.options.Homepage, path.Ext(r.options.Homepage)))
+	} else if !r.options.DisableStatic && hasPathPrefix(req.URL.Path, r.options.PrefixStatic) {
 		// 静态资源
 		r.serveFile(rw, req)
-	} else if strings.HasPrefix(req.URL.Path, Prefix_Upload) {
+	} else if r.tus != nil && hasPathPrefix(req.URL.Path, r.options.PrefixTus) {
+		// 可续传上传，需在上传路由前判断，默认的前缀位于上传路由前缀下
+		r.tus.ServeHTTP(rw, withContext(req, ctx))
+	} else if !r.options.DisableUpload && hasPathPrefix(req.URL.Path, r.options.PrefixUpload) {
 		// 上传文件
-		r.serveUpload(rw, req)
+		r.serveUpload(ctx)
+	} else {
+		// 处理API访问逻辑，包括自动路由和自定义格式的路由
+		r.serveAPI(rw, req, ctx)
 	}
 }
 
-func (r *myRouter) serveUpload(rw http.ResponseWriter, req *http.Request) {
-	r.uploadOneFile(rw, req)
-	// r.uploadMultiFiles(rw, req)
-}
-
-// func (r *RouterRegister) uploadMultiFiles(rw http.ResponseWriter, req *http.Request) {
-// 	req.ParseMultipartForm(32 << 20)
-// 	multipartForm := req.MultipartForm
-// 	files := multipartForm.File["uploadfile"]
-
-// 	var filenames []string
-// 	for _, file := range files {
-
-// 		src, err := file.Open()
-// 		if err != nil {
-// 			Error("Upload-> open file error:", err.Error())
-// 			return
-// 		}
-// 		defer src.Close()
-
-// 		filename, err := r.getUploadFileName(req.URL.Path, file.Filename)
-// 		if err != nil {
-// 			Error("Upload-> get file name error:", err.Error())
-// 			return
-// 		}
-// 		dst, err := os.Create(filename)
-// 		if err != nil {
-// 			Error("Upload-> create file", filename, "error:", err.Error())
-// 			return
-// 		}
-// 		defer dst.Close()
-
-// 		if _, err = io.Copy(dst, src); err != nil {
-// 			Error("Upload-> Copy file", filename, "error:", err.Error())
-// 			return
-// 		}
-// 		filenames = append(filenames, filename)
-
-// 	}
-
-// 	json.NewEncoder(rw).Encode(filenames)
-// }
-
-func (r *myRouter) uploadOneFile(rw http.ResponseWriter, req *http.Request) {
-	req.ParseMultipartForm(32 << 20)
-
-	file, handler, err := req.FormFile("uploadfile")
-	if err != nil {
+// serveUpload 保存上传的文件，文件保存到存储中与URL相同的目录，以JSON返回upload.Result，
+// 如 POST /upload/avatar 保存到avatar目录，默认的存储为 <StaticFolder>/upload。
+// 结果无法输出时删除本次保存的文件，避免客户端不知道的文件留在存储中。
+func (r *myRouter) serveUpload(ctx context.Context) {
+	rw, req := ctx.Response(), ctx.Request()
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		r.renderError(ctx, NewHTTPError(http.StatusMethodNotAllowed, ""))
 		return
 	}
-	defer file.Close()
 
-	uploadFilename, err := r.getUploadFileName(req.URL.Path, handler.Filename)
+	dir := strings.TrimPrefix(path.Clean(req.URL.Path), r.options.PrefixUpload)
+	result, err := r.uploader.Save(req, dir)
 	if err != nil {
+		r.renderError(ctx, err)
 		return
 	}
 
-	f, err := os.OpenFile(uploadFilename, os.O_WRONLY|os.O_CREATE, 0666)
+	enc := render.Default()
+	body, err := enc.Marshal(result)
 	if err != nil {
+		r.uploader.Remove(result)
+		r.renderError(ctx, err)
 		return
 	}
-	defer f.Close()
-
-	_, err = io.Copy(f, file)
-	if err != nil {
-		return
+	rw.Header().Set("Content-Type", enc.Type())
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(body); err != nil {
+		r.uploader.Remove(result)
+		if l := r.logger(); l != nil {
+			l.Warn("upload %s: write response: %v, saved files removed", req.URL.Path, err)
+		}
 	}
-
-	json.NewEncoder(rw).Encode(uploadFilename)
 }
 
-func (r *myRouter) getUploadFileName(url, filename string) (uploadFilename string, err error) {
-	if len(filename) == 0 {
-		err = errors.New("文件名不能为空")
-	}
-	url = url[1:]
-
-	var folders []string
-	if strings.Contains(url, "/") {
-		folders = strings.Split(url, "/")
-	} else if strings.Contains(url, "\\") {
-		folders = strings.Split(url, "\\")
-	} else {
-		folders = []string{url}
-	}
-	p := path.Join(folders...)
-	p = path.Join(r.staticFolder, p)
-
-	var infos []string
-	if strings.Contains(filename, ".") {
-		infos = strings.Split(filename, ".")
-	} else {
-		infos = []string{filename}
-	}
-	if len(infos) == 1 {
-		err = errors.New("不支持的文件格式")
+// serveHtml 渲染项目目录下的页面，页面数据为当前请求。
+func (r *myRouter) serveHtml(ctx context.Context, name string) {
+	rw := ctx.Response()
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err := r.pages.Render(rw, name, ctx.Request())
+	if os.IsNotExist(err) {
+		rw.Header().Del("Content-Type")
+		http.NotFound(rw, ctx.Request())
 		return
 	}
+	if err != nil {
+		r.renderError(ctx, err)
+	}
+}
 
-	h := md5.New()
-	io.WriteString(h, strconv.FormatInt(time.Now().Unix(), 10))
-	io.WriteString(h, infos[0])
-	f := fmt.Sprintf("%x.%s", h.Sum(nil), infos[len(infos)-1])
+func (r *myRouter) View() view.View {
+	return r.views
+}
 
-	uploadFilename = path.Join(p, f)
-	return
+func (r *myRouter) Uploader() upload.Uploader {
+	return r.uploader
 }
 
-func (r *myRouter) serveHtml(rw http.ResponseWriter, req *http.Request, url string) {
-	t, err := template.ParseFiles(url)
-	if err != nil {
-		http.NotFound(rw, req)
-		return
+func (r *myRouter) Close() {
+	if r.stopTus != nil {
+		r.stopTus()
 	}
-
-	t.Execute(rw, nil)
 }
 
 func (r *myRouter) serveFile(rw http.ResponseWriter, req *http.Request) {
-	var url = req.URL.Path
-	url = url[1:]
+	// 静态资源路由前缀映射到静态资源目录
+	var url = strings.TrimPrefix(req.URL.Path, r.options.PrefixStatic)
+	url = path.Join(r.options.StaticFolder, path.Clean("/"+url))
 	http.ServeFile(rw, req, url)
 }
 
+// hasPathPrefix 判断url是否位于路由前缀下，按路径段匹配，如 /upload 不匹配 /uploads，前缀为空时不匹配。
+func hasPathPrefix(url, prefix string) bool {
+	if len(prefix) == 0 {
+		return false
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	return url == prefix || strings.HasPrefix(url, prefix+"/")
+}
+
 func (r *myRouter) serveAPI(rw http.ResponseWriter, req *http.Request, ctx context.Context) {
-	route, err := r.findRouterInfo(req.URL.Path)
+	route, params, allow, err := r.findRouterInfo(req.Method, req.URL.EscapedPath())
+	if err == errMethodNotAllowed {
+		rw.Header().Set("Allow", strings.Join(allow, ", "))
+		if req.Method == http.MethodOptions {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		r.renderError(ctx, NewHTTPError(http.StatusMethodNotAllowed, ""))
+		return
+	}
 	if err != nil {
-		http.NotFound(rw, req)
+		r.renderError(ctx, NewHTTPError(http.StatusNotFound, ""))
 		return
 	}
+	ctx.SetParams(params)
+
+	route.handler(ctx)
+}
 
+func (r *myRouter) serveController(route *route, ctx context.Context) {
 	var execController controller.Controller
 	refV := reflect.New(route.controllerType)
 	execController, ok := refV.Interface().(controller.Controller)
 	if !ok {
-		http.NotFound(rw, req)
+		r.renderError(ctx, NewHTTPError(http.StatusNotFound, ""))
 		return
 	}
+	if err := r.inject(refV.Elem(), route.injects); err != nil {
+		e := NewHTTPError(http.StatusInternalServerError, "")
+		e.Err = err
+		r.renderError(ctx, e)
+		return
+	}
+	if setter, ok := execController.(controller.ViewSetter); ok {
+		setter.SetView(r.views)
+	}
 	execController.Init(ctx)
+	if finisher, ok := execController.(controller.Finisher); ok {
+		defer finisher.Finish()
+	}
+	if preparer, ok := execController.(controller.Preparer); ok {
+		if err := preparer.Prepare(); err != nil {
+			r.renderError(ctx, err)
+			return
+		}
+	}
 
-	vc := reflect.ValueOf(execController)
-	method := vc.MethodByName(route.methodName)
-	if !method.IsValid() {
-		http.NotFound(rw, req)
+	methodInput, err := r.getMethodStructParams(route, ctx)
+	if errs, ok := err.(validation.Errors); ok {
+		e := NewHTTPError(http.StatusUnprocessableEntity, "validation failed")
+		e.Errors = errs
+		e.Err = err
+		r.renderError(ctx, e)
+		return
+	}
+	if _, ok := err.(StatusCoder); ok {
+		// 如binding.BodyTooLargeError，使用错误的状态码
+		r.renderError(ctx, err)
 		return
 	}
-
-	methodInput, err := r.getMethodStructParams(route, req)
 	if err != nil {
-		json.NewEncoder(ctx.Response()).Encode(err.Error())
+		e := NewHTTPError(http.StatusBadRequest, err.Error())
+		e.Err = err
+		r.renderError(ctx, e)
 		return
 	}
 
-	inputs := []reflect.Value{}
+	inputs := []reflect.Value{refV}
 	if methodInput != nil {
-		expectType := method.Type().In(0)
-		provideType := reflect.TypeOf(methodInput)
-		if expectType != provideType {
-			return
-		}
 		inputs = append(inputs, reflect.ValueOf(methodInput))
 	}
+	if route.method.Type().NumIn() != len(inputs) {
+		e := NewHTTPError(http.StatusInternalServerError, "")
+		e.Err = genError(fmt.Sprintf("%s.%s: unsupported method signature %s", route.controllerType, route.methodName, route.method.Type()))
+		r.renderError(ctx, e)
+		return
+	}
 
-	method.Call(inputs)
+	outs := route.method.Call(inputs)
+	r.handleResults(ctx, outs)
 }
 
-// 通过路由信息，转换请求数据为Struct类型的参数
-func (r *myRouter) getMethodStructParams(route route, req *http.Request) (interface{}, error) {
+// 通过路由信息，转换请求数据为Struct类型的参数，见binding.Bind，
+// 并按字段的validate tag校验，见validation.Validate
+func (r *myRouter) getMethodStructParams(route *route, ctx context.Context) (interface{}, error) {
 	if route.methodInputType == nil {
 		return nil, nil
 	}
 
-	body, err := ioutil.ReadAll(req.Body)
-	req.Body.Close()
+	mit := reflect.New(route.methodInputType)
+	err := binding.Bind(ctx, mit.Interface())
 	if err != nil {
 		return nil, err
 	}
-
-	mit := reflect.New(route.methodInputType)
-	err = json.Unmarshal(body, mit.Interface())
+	err = validation.Validate(mit.Interface())
 	if err != nil {
 		return nil, err
 	}
 	return mit.Elem().Interface(), nil
 }
 
-func (r *myRouter) findRouterInfo(url string) (route route, err error) {
-	// 格式：/api/account/login
-	pattern := strings.ToLower(url)
+var errMethodNotAllowed = errors.New("Method not allowed")
 
-	if routeInfo, ok := r.routerMap[pattern]; ok {
-		route = routeInfo
+// findRouterInfo 查找路由，路径匹配但HTTP方法不匹配时返回errMethodNotAllowed及允许的方法。
+// 未注册HEAD时使用GET的路由。
+func (r *myRouter) findRouterInfo(method, escapedPath string) (route *route, params context.Params, allow []string, err error) {
+	// 格式：/api/account/login
+	n, ps := r.tree.find(escapedPath)
+	if n == nil {
+		err = errors.New("Route not found")
 		return
 	}
 
-	err = errors.New("Route not found")
+	route = n.lookup(method)
+	if route == nil && method == http.MethodHead {
+		route = n.lookup(http.MethodGet)
+	}
+	if route == nil {
+		allow = n.allowed()
+		err = errMethodNotAllowed
+		return
+	}
+	params = ps
 	return
 }
 
-func (r *myRouter) AddAutoRouter(c controller.Controller) {
-	reflectVal := reflect.ValueOf(c)
-	rt := reflectVal.Type()
-	ct := reflect.Indirect(reflectVal).Type()
-	controllerName := strings.TrimSuffix(ct.Name(), Suffix_Controller)
-
-	for i := 0; i < rt.NumMethod(); i++ {
-		route := route{}
-		route.controllerType = ct
-		route.methodName = rt.Method(i).Name
-		pattern := path.Join(Prefix_API, strings.ToLower(controllerName), strings.ToLower(rt.Method(i).Name))
-		route.pattern = pattern
-
-		// 提取方法的第一个参数信息，如果是Struct，则保存到路由信息，用户访问时json数据转换为Struct
-		mt := rt.Method(i).Type
-
-		if mt.NumIn() >= 2 {
-			it := mt.In(1)
-			if it.Kind() == reflect.Struct {
-				route.methodInputType = it
-			}
+// splitVerb 拆分方法名中表示HTTP方法的前缀，如 GetUser 拆分为 GET 和 User。
+func splitVerb(name string) (verb, action string) {
+	for _, p := range verbPrefixes {
+		if !strings.HasPrefix(name, p) {
+			continue
+		}
+		rest := name[len(p):]
+		if len(rest) == 0 || unicode.IsUpper([]rune(rest)[0]) {
+			return strings.ToUpper(p), rest
 		}
+	}
+	return methodAny, name
+}
 
-		r.routerMap[pattern] = route
+// parseVerbs 解析逗号分隔的HTTP方法，如 "get,post"。
+func parseVerbs(s string) []string {
+	var verbs []string
+	for _, v := range strings.Split(s, ",") {
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if v == methodAny {
+			verbs = append(verbs, v)
+			continue
+		}
+		valid := false
+		for _, m := range HTTPMethods {
+			if m == v {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			panic(genError(fmt.Sprintf("unknown http method %s", v)))
+		}
+		verbs = append(verbs, v)
 	}
+	return verbs
 }
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"letgo/context"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// RouteInfo 已注册路由的信息。
+type RouteInfo struct {
+	Name       string `json:"name,omitempty"`
+	Pattern    string `json:"pattern"`
+	Method     string `json:"method"` // HTTP方法，不限方法时为 *
+	Controller string `json:"controller,omitempty"`
+	Action     string `json:"action,omitempty"` // 控制器的方法名
+	InputType  string `json:"inputType,omitempty"`
+	OutputType string `json:"outputType,omitempty"`
+}
+
+// Routes 按注册顺序返回已注册的路由，不包含路由内部使用的路由。
+func (r *myRouter) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(r.entries))
+	for _, e := range r.entries {
+		if e.route.internal {
+			continue
+		}
+		routes = append(routes, RouteInfo{
+			Name:       e.route.name,
+			Pattern:    e.route.pattern,
+			Method:     e.method,
+			Controller: typeName(e.route.controllerType),
+			Action:     e.route.methodName,
+			InputType:  typeName(e.route.methodInputType),
+			OutputType: typeName(e.route.methodOutputType),
+		})
+	}
+	return routes
+}
+
+func (r *myRouter) Errors() []error {
+	return r.errs
+}
+
+func (r *myRouter) MustValid() {
+	if len(r.errs) == 0 {
+		return
+	}
+	msgs := make([]string, len(r.errs))
+	for i, err := range r.errs {
+		msgs[i] = err.Error()
+	}
+	panic(genError(fmt.Sprintf("%d route conflicts:\n%s", len(r.errs), strings.Join(msgs, "\n"))))
+}
+
+// PrintRoutes 以表格的形式输出路由，用于启动时或命令行中查看路由表。
+func PrintRoutes(w io.Writer, routes []RouteInfo) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATTERN\tNAME\tCONTROLLER\tACTION\tINPUT\tOUTPUT")
+	for _, ri := range routes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", ri.Method, ri.Pattern, dash(ri.Name), dash(ri.Controller), dash(ri.Action), dash(ri.InputType), dash(ri.OutputType))
+	}
+	tw.Flush()
+}
+
+// serveRoutes 注册查看路由表的调试路由。
+func (r *myRouter) serveRoutes() {
+	if len(r.options.RoutesPath) == 0 {
+		return
+	}
+	r.addInternalRoute(r.options.RoutesPath, func(ctx context.Context) {
+		rw := ctx.Response()
+		rw.Header().Set("Content-Type", "application/json; charset=utf-8")
+		enc := json.NewEncoder(rw)
+		enc.SetIndent("", "  ")
+		enc.Encode(r.Routes())
+	})
+}
+
+// describe 路由的来源，用于错误信息。
+func (rt *route) describe() string {
+	if rt.controllerType != nil {
+		return fmt.Sprintf("%s.%s", typeName(rt.controllerType), rt.methodName)
+	}
+	return "handler"
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
+
+func dash(s string) string {
+	if len(s) == 0 {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,267 @@
+package router
+
+import (
+	"fmt"
+	"letgo/context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 路由格式中支持的参数类型约束，如 /api/user/:id:int。
+var paramCheckers = map[string]func(string) bool{
+	"int": func(s string) bool {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	},
+	"string": func(s string) bool {
+		return len(s) > 0
+	},
+	"alpha": func(s string) bool {
+		for _, c := range s {
+			if (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') {
+				return false
+			}
+		}
+		return len(s) > 0
+	},
+}
+
+// node 路由前缀树的节点，每个节点对应路径中的一段。
+// 匹配时静态段优先，其次是参数段，最后是通配段。参数段中正则约束优先，
+// 其次是类型约束，最后是无约束的参数，同一级别的按注册顺序匹配。
+type node struct {
+	static   map[string]*node // 静态子节点，key为小写的路径段
+	params   []*node          // 参数子节点，按priority排序
+	wildcard *node            // 通配子节点，匹配剩余的全部路径
+
+	param  string            // 参数名
+	rule   string            // 参数约束的原始写法，用于复用相同的节点
+	check  func(string) bool // 参数约束
+	routes map[string]*route // 按HTTP方法保存的路由，methodAny表示不限方法
+}
+
+func newNode() *node {
+	return &node{static: make(map[string]*node)}
+}
+
+// lookup 按HTTP方法查找节点上的路由，未单独注册的方法使用methodAny的路由。
+func (n *node) lookup(method string) *route {
+	if r, ok := n.routes[method]; ok {
+		return r
+	}
+	return n.routes[methodAny]
+}
+
+// allowed 节点上允许的HTTP方法，按HTTPMethods的顺序排列，用于Allow响应头。
+func (n *node) allowed() []string {
+	var methods []string
+	for _, m := range HTTPMethods {
+		if _, ok := n.routes[m]; ok {
+			methods = append(methods, m)
+			continue
+		}
+		switch m {
+		case http.MethodHead:
+			if _, ok := n.routes[http.MethodGet]; ok {
+				methods = append(methods, m)
+			}
+		case http.MethodOptions:
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// priority 参数节点的匹配优先级，数值小的先匹配。
+func (n *node) priority() int {
+	switch {
+	case strings.HasPrefix(n.rule, "("):
+		return 0
+	case len(n.rule) > 0:
+		return 1
+	}
+	return 2
+}
+
+// insert 添加路由，pattern格式：
+// /api/user/:id         命名参数
+// /api/user/:id:int     带类型约束的参数
+// /api/user/:id([0-9]+) 带正则约束的参数
+// /www/*filepath        通配剩余路径
+// 同一位置已有其它参数时，新的参数与其可能匹配相同的值，返回的overlaps说明重叠的参数，
+// 匹配时按priority的顺序尝试。
+func (n *node) insert(method, pattern string, r *route) (overlaps []string, err error) {
+	segs := splitPath(pattern)
+	cur := n
+	for i, seg := range segs {
+		switch seg[0] {
+		case ':':
+			name, rule, check, err := parseParam(seg[1:])
+			if err != nil {
+				return overlaps, genError(fmt.Sprintf("pattern %s: %v", pattern, err))
+			}
+			var child *node
+			for _, p := range cur.params {
+				if p.rule != rule {
+					continue
+				}
+				if p.param != name {
+					// 约束相同但名称不同的参数无法区分
+					return overlaps, genError(fmt.Sprintf("pattern %s: param :%s is ambiguous with :%s", pattern, name, p.param))
+				}
+				child = p
+				break
+			}
+			if child == nil {
+				child = newNode()
+				child.param = name
+				child.rule = rule
+				child.check = check
+				for _, p := range cur.params {
+					overlaps = append(overlaps, fmt.Sprintf("pattern %s: param %s overlaps %s", pattern, seg, p.describe()))
+				}
+				cur.insertParam(child)
+			}
+			cur = child
+		case '*':
+			if i != len(segs)-1 {
+				return overlaps, genError(fmt.Sprintf("pattern %s: wildcard must be the last segment", pattern))
+			}
+			name := seg[1:]
+			if len(name) == 0 {
+				return overlaps, genError(fmt.Sprintf("pattern %s: wildcard must be named", pattern))
+			}
+			if cur.wildcard == nil {
+				cur.wildcard = newNode()
+				cur.wildcard.param = name
+			} else if cur.wildcard.param != name {
+				return overlaps, genError(fmt.Sprintf("pattern %s: wildcard *%s conflicts with *%s", pattern, name, cur.wildcard.param))
+			}
+			cur = cur.wildcard
+		default:
+			key := strings.ToLower(seg)
+			child, ok := cur.static[key]
+			if !ok {
+				child = newNode()
+				cur.static[key] = child
+			}
+			cur = child
+		}
+	}
+	if cur.routes == nil {
+		cur.routes = make(map[string]*route)
+	}
+	if exist, ok := cur.routes[method]; ok {
+		return overlaps, genError(fmt.Sprintf("pattern %s (%s) conflicts with %s registered by %s", pattern, method, exist.pattern, exist.describe()))
+	}
+	cur.routes[method] = r
+	return overlaps, nil
+}
+
+// insertParam 按优先级插入参数子节点，相同优先级的排在已有节点之后。
+func (n *node) insertParam(child *node) {
+	i := len(n.params)
+	for i > 0 && n.params[i-1].priority() > child.priority() {
+		i--
+	}
+	n.params = append(n.params, nil)
+	copy(n.params[i+1:], n.params[i:])
+	n.params[i] = child
+}
+
+// describe 参数节点在路由格式中的写法，用于错误信息。
+func (n *node) describe() string {
+	if strings.HasPrefix(n.rule, "(") || len(n.rule) == 0 {
+		return ":" + n.param + n.rule
+	}
+	return ":" + n.param + ":" + n.rule
+}
+
+// find 查找与转义的路径匹配的节点及捕获的参数，按 / 拆分后再反转义每一段，
+// 参数值可以包含转义的 /，如 /api/file/a%2Fb 中的参数值为 a/b。
+func (n *node) find(escapedPath string) (*node, context.Params) {
+	segs := splitPath(escapedPath)
+	for i, seg := range segs {
+		v, err := url.PathUnescape(seg)
+		if err != nil {
+			return nil, nil
+		}
+		segs[i] = v
+	}
+	return n.match(segs, nil)
+}
+
+func (n *node) match(segs []string, params context.Params) (*node, context.Params) {
+	if len(segs) == 0 {
+		if len(n.routes) > 0 {
+			return n, params
+		}
+		if n.wildcard != nil && len(n.wildcard.routes) > 0 {
+			return n.wildcard, append(params, context.Param{Key: n.wildcard.param})
+		}
+		return nil, nil
+	}
+
+	seg := segs[0]
+	if child, ok := n.static[strings.ToLower(seg)]; ok {
+		if found, ps := child.match(segs[1:], params); found != nil {
+			return found, ps
+		}
+	}
+	for _, child := range n.params {
+		if child.check != nil && !child.check(seg) {
+			continue
+		}
+		if found, ps := child.match(segs[1:], append(params, context.Param{Key: child.param, Value: seg})); found != nil {
+			return found, ps
+		}
+	}
+	if n.wildcard != nil && len(n.wildcard.routes) > 0 {
+		return n.wildcard, append(params, context.Param{Key: n.wildcard.param, Value: strings.Join(segs, "/")})
+	}
+	return nil, nil
+}
+
+// parseParam 解析参数段，返回参数名、约束写法和约束函数。
+func parseParam(seg string) (name, rule string, check func(string) bool, err error) {
+	if i := strings.IndexByte(seg, '('); i >= 0 {
+		if !strings.HasSuffix(seg, ")") {
+			err = fmt.Errorf("unclosed regexp in :%s", seg)
+			return
+		}
+		name, rule = seg[:i], seg[i:]
+		var re *regexp.Regexp
+		re, err = regexp.Compile("^" + rule + "$")
+		if err != nil {
+			return
+		}
+		check = re.MatchString
+	} else if i := strings.IndexByte(seg, ':'); i >= 0 {
+		name, rule = seg[:i], seg[i+1:]
+		var ok bool
+		check, ok = paramCheckers[rule]
+		if !ok {
+			err = fmt.Errorf("unknown param type %s", rule)
+			return
+		}
+	} else {
+		name = seg
+	}
+	if len(name) == 0 {
+		err = fmt.Errorf("param must be named")
+	}
+	return
+}
+
+func splitPath(p string) []string {
+	var segs []string
+	for _, seg := range strings.Split(p, "/") {
+		if len(seg) > 0 {
+			segs = append(segs, seg)
+		}
+	}
+	return segs
+}
@@ -0,0 +1,215 @@
+package router
+
+import (
+	"letgo/context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func newTestTree(t *testing.T, patterns ...string) (*node, map[string]*route) {
+	t.Helper()
+	tree := newNode()
+	routes := make(map[string]*route)
+	for _, p := range patterns {
+		r := &route{pattern: p}
+		if _, err := tree.insert(methodAny, p, r); err != nil {
+			t.Fatalf("insert %s: %v", p, err)
+		}
+		routes[p] = r
+	}
+	return tree, routes
+}
+
+func TestTreeFind(t *testing.T) {
+	tree, routes := newTestTree(t,
+		"/api/user/list",
+		"/api/user/:name",
+		"/api/user/:id:int",
+		"/api/user/:code([a-z]{2}[0-9]+)",
+		"/api/user/:id:int/orders",
+		"/api/user/:name/profile",
+		"/api/file/:path",
+		"/www/*filepath",
+		"/static/*filepath",
+		"/static/css/site.css",
+	)
+
+	tests := []struct {
+		path    string
+		pattern string // 为空时表示未匹配
+		params  context.Params
+	}{
+		// 静态段优先于参数段
+		{"/api/user/list", "/api/user/list", nil},
+		{"/API/User/List", "/api/user/list", nil},
+		// 约束参数优先于无约束的参数，与注册顺序无关
+		{"/api/user/42", "/api/user/:id:int", context.Params{{Key: "id", Value: "42"}}},
+		{"/api/user/ab12", "/api/user/:code([a-z]{2}[0-9]+)", context.Params{{Key: "code", Value: "ab12"}}},
+		{"/api/user/bob", "/api/user/:name", context.Params{{Key: "name", Value: "bob"}}},
+		// 回溯：42匹配int参数，但只有 :name 下有profile
+		{"/api/user/42/profile", "/api/user/:name/profile", context.Params{{Key: "name", Value: "42"}}},
+		{"/api/user/42/orders", "/api/user/:id:int/orders", context.Params{{Key: "id", Value: "42"}}},
+		{"/api/user/bob/orders", "", nil},
+		// 参数值反转义，可以包含 /
+		{"/api/file/a%2Fb", "/api/file/:path", context.Params{{Key: "path", Value: "a/b"}}},
+		{"/api/file/a%20b", "/api/file/:path", context.Params{{Key: "path", Value: "a b"}}},
+		{"/api/file/a%zz", "", nil},
+		// 通配段匹配剩余的路径，优先级最低
+		{"/www/js/app.js", "/www/*filepath", context.Params{{Key: "filepath", Value: "js/app.js"}}},
+		{"/www", "/www/*filepath", context.Params{{Key: "filepath"}}},
+		{"/static/css/site.css", "/static/css/site.css", nil},
+		{"/static/css/other.css", "/static/*filepath", context.Params{{Key: "filepath", Value: "css/other.css"}}},
+		{"/api/order/1", "", nil},
+		{"/", "", nil},
+	}
+	for _, tt := range tests {
+		n, params := tree.find(tt.path)
+		if len(tt.pattern) == 0 {
+			if n != nil {
+				t.Errorf("find %s: got %s, want no match", tt.path, n.lookup(methodAny).pattern)
+			}
+			continue
+		}
+		if n == nil {
+			t.Errorf("find %s: no match, want %s", tt.path, tt.pattern)
+			continue
+		}
+		if got := n.lookup(methodAny); got != routes[tt.pattern] {
+			t.Errorf("find %s: got %s, want %s", tt.path, got.pattern, tt.pattern)
+		}
+		if !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("find %s: params %v, want %v", tt.path, params, tt.params)
+		}
+	}
+}
+
+func TestTreeConstraints(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/n/:id:int", "/n/-7", true},
+		{"/n/:id:int", "/n/7x", false},
+		{"/n/:name:alpha", "/n/Bob", true},
+		{"/n/:name:alpha", "/n/bob1", false},
+		{"/n/:name:string", "/n/x", true},
+		{"/n/:v([0-9]{4})", "/n/2024", true},
+		{"/n/:v([0-9]{4})", "/n/20245", false},
+	}
+	for _, tt := range tests {
+		tree, _ := newTestTree(t, tt.pattern)
+		if n, _ := tree.find(tt.path); (n != nil) != tt.match {
+			t.Errorf("%s find %s: match %v, want %v", tt.pattern, tt.path, n != nil, tt.match)
+		}
+	}
+}
+
+func TestTreeInsertErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+	}{
+		{"duplicate", []string{"/a/:id", "/a/:id"}},
+		{"ambiguous params", []string{"/a/:id", "/a/:name"}},
+		{"ambiguous typed params", []string{"/a/:id:int", "/a/:n:int"}},
+		{"wildcard not last", []string{"/a/*rest/b"}},
+		{"unnamed wildcard", []string{"/a/*"}},
+		{"wildcard names", []string{"/a/*p", "/a/*q"}},
+		{"unknown type", []string{"/a/:id:uuid"}},
+		{"bad regexp", []string{"/a/:id([0-9]"}},
+		{"unnamed param", []string{"/a/:"}},
+	}
+	for _, tt := range tests {
+		tree := newNode()
+		var err error
+		for _, p := range tt.patterns {
+			if _, err = tree.insert(methodAny, p, &route{pattern: p}); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			t.Errorf("%s: insert %v: want error", tt.name, tt.patterns)
+		}
+	}
+}
+
+func TestTreeOverlaps(t *testing.T) {
+	tree := newNode()
+	tests := []struct {
+		pattern  string
+		overlaps int
+	}{
+		{"/a/:name", 0},
+		{"/a/:id:int", 1},
+		{"/a/:code([0-9]+)", 2},
+		{"/a/:id:int/b", 0}, // 复用已有的节点
+		{"/a/list", 0},
+	}
+	for _, tt := range tests {
+		overlaps, err := tree.insert(methodAny, tt.pattern, &route{pattern: tt.pattern})
+		if err != nil {
+			t.Fatalf("insert %s: %v", tt.pattern, err)
+		}
+		if len(overlaps) != tt.overlaps {
+			t.Errorf("insert %s: overlaps %v, want %d", tt.pattern, overlaps, tt.overlaps)
+		}
+	}
+}
+
+func TestRouterErrors(t *testing.T) {
+	r := NewRouterWithOptions(DefaultOptions())
+	ok := func(ctx context.Context) {}
+	r.Get("/api/a/:id", ok)
+	r.Get("/api/a/:id", ok)
+	r.Get("/api/a/:name", ok)
+	r.Post("/api/a/:id", ok)
+	if n := len(r.Errors()); n != 2 {
+		t.Fatalf("errors %v, want 2", r.Errors())
+	}
+	if n := len(r.Routes()); n != 2 {
+		t.Errorf("routes %d, want 2", n)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("MustValid: want panic")
+		}
+	}()
+	r.MustValid()
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	opts := DefaultOptions()
+	opts.DisableStatic = true
+	opts.DisableUpload = true
+	r := NewRouterWithOptions(opts)
+	ok := func(ctx context.Context) { ctx.Response().WriteHeader(http.StatusOK) }
+	r.Get("/api/item/:id:int", ok)
+	r.Put("/api/item/:id:int", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		allow  string
+	}{
+		{http.MethodGet, "/api/item/1", http.StatusOK, ""},
+		{http.MethodHead, "/api/item/1", http.StatusOK, ""},
+		{http.MethodPut, "/api/item/1", http.StatusOK, ""},
+		{http.MethodPost, "/api/item/1", http.StatusMethodNotAllowed, "GET, HEAD, OPTIONS, PUT"},
+		{http.MethodOptions, "/api/item/1", http.StatusNoContent, "GET, HEAD, OPTIONS, PUT"},
+		{http.MethodGet, "/api/item/x", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rw := httptest.NewRecorder()
+		r.ServeHTTP(rw, httptest.NewRequest(tt.method, tt.path, nil))
+		if rw.Code != tt.status {
+			t.Errorf("%s %s: status %d, want %d", tt.method, tt.path, rw.Code, tt.status)
+		}
+		if got := rw.Header().Get("Allow"); got != tt.allow {
+			t.Errorf("%s %s: Allow %q, want %q", tt.method, tt.path, got, tt.allow)
+		}
+	}
+}
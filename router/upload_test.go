@@ -0,0 +1,184 @@
+package router
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"letgo/context"
+	"letgo/storage"
+	"letgo/tus"
+	"letgo/upload"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func newUploadRouter(t *testing.T, enableTus, disableUpload bool) Router {
+	t.Helper()
+	opts := DefaultOptions()
+	opts.DisableStatic = true
+	opts.UploadStorage = storage.NewMemory("/files")
+	opts.TusFolder = t.TempDir()
+	opts.EnableTus = enableTus
+	opts.DisableUpload = disableUpload
+	r := NewRouterWithOptions(opts)
+	t.Cleanup(r.Close)
+	return r
+}
+
+func TestTusOptIn(t *testing.T) {
+	tests := []struct {
+		name          string
+		enableTus     bool
+		disableUpload bool
+		created       bool
+	}{
+		{"default", false, false, false},
+		{"enabled", true, false, true},
+		{"upload disabled", true, true, false},
+	}
+	for _, tt := range tests {
+		r := newUploadRouter(t, tt.enableTus, tt.disableUpload)
+		req := httptest.NewRequest(http.MethodPost, Prefix_Tus, nil)
+		req.Header.Set(tus.Header_Resumable, tus.Version)
+		req.Header.Set(tus.Header_UploadLength, "10")
+		rw := httptest.NewRecorder()
+		r.ServeHTTP(rw, req)
+		if created := rw.Code == http.StatusCreated; created != tt.created {
+			t.Errorf("%s: status %d, created %v, want %v", tt.name, rw.Code, created, tt.created)
+		}
+	}
+}
+
+func TestTusMaxSize(t *testing.T) {
+	r := newUploadRouter(t, true, false)
+	req := httptest.NewRequest(http.MethodOptions, Prefix_Tus, nil)
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+	if got := rw.Header().Get(tus.Header_MaxSize); got != "33554432" {
+		t.Errorf("Tus-Max-Size %q, want the upload limit 33554432", got)
+	}
+}
+
+// tusUpload 创建上传并一次写入全部内容，返回PATCH的响应。
+func tusUpload(t *testing.T, r Router, filename string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, Prefix_Tus, nil)
+	req.Header.Set(tus.Header_Resumable, tus.Version)
+	req.Header.Set(tus.Header_UploadLength, strconv.Itoa(len(data)))
+	req.Header.Set(tus.Header_UploadMetadata, "filename "+base64.StdEncoding.EncodeToString([]byte(filename)))
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+	if rw.Code != http.StatusCreated {
+		t.Fatalf("create %s: status %d", filename, rw.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPatch, rw.Header().Get("Location"), bytes.NewReader(data))
+	req.Header.Set(tus.Header_Resumable, tus.Version)
+	req.Header.Set(tus.Header_UploadOffset, "0")
+	req.Header.Set("Content-Type", tus.MIME_OffsetOctetStream)
+	rw = httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+	return rw
+}
+
+func TestTusCompleteChecks(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+	r := newUploadRouter(t, true, false)
+
+	if rw := tusUpload(t, r, "evil.html", png); rw.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("evil.html: status %d, want %d", rw.Code, http.StatusUnsupportedMediaType)
+	}
+	rw := tusUpload(t, r, "ok.png", png)
+	var result upload.Result
+	if err := json.Unmarshal(rw.Body.Bytes(), &result); rw.Code != http.StatusOK || err != nil || len(result.Files) != 1 {
+		t.Fatalf("ok.png: status %d, want %d: %s", rw.Code, http.StatusOK, rw.Body)
+	}
+	if f := result.Files[0]; f.ContentType != "image/png" || rw.Header().Get(tus.Header_UploadFile) != f.URL {
+		t.Errorf("ok.png: file %+v, Upload-File %q", f, rw.Header().Get(tus.Header_UploadFile))
+	}
+}
+
+func TestUploadIndexOptIn(t *testing.T) {
+	r := newUploadRouter(t, false, false)
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	fw, _ := w.CreateFormFile("file", "a.txt")
+	fw.Write([]byte("hello"))
+	w.Close()
+	req := httptest.NewRequest(http.MethodPost, Prefix_Upload, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+
+	var result upload.Result
+	if err := json.Unmarshal(rw.Body.Bytes(), &result); err != nil || len(result.Files) != 1 {
+		t.Fatalf("upload: status %d, %s", rw.Code, rw.Body)
+	}
+	// 默认不使用内容索引
+	if _, err := r.Uploader().Lookup(result.Files[0].Hash); !errors.Is(err, storage.ErrNotExist) {
+		t.Errorf("Lookup: %v, want ErrNotExist", err)
+	}
+}
+
+func TestTusErrorFormat(t *testing.T) {
+	r := newUploadRouter(t, true, false)
+	req := httptest.NewRequest(http.MethodHead, Prefix_Tus+"/0123456789abcdef0123456789abcdef", nil)
+	req.Header.Set(tus.Header_Resumable, tus.Version)
+	rw := httptest.NewRecorder()
+	r.ServeHTTP(rw, req)
+	if rw.Code != http.StatusNotFound {
+		t.Fatalf("status %d, want %d", rw.Code, http.StatusNotFound)
+	}
+	// 与上传路由的错误相同，为JSON格式
+	var e HTTPError
+	if err := json.Unmarshal(rw.Body.Bytes(), &e); err != nil || e.Status != http.StatusNotFound {
+		t.Errorf("body %q (%s): %v", rw.Body, rw.Header().Get("Content-Type"), err)
+	}
+}
+
+func TestDispatchPrefix(t *testing.T) {
+	opts := DefaultOptions()
+	opts.StaticFolder = t.TempDir()
+	opts.UploadStorage = storage.NewMemory("/files")
+	opts.TusFolder = t.TempDir()
+	opts.EnableTus = true
+	r := NewRouterWithOptions(opts)
+	t.Cleanup(r.Close)
+	for _, p := range []string{"/uploads", "/wwwfoo"} {
+		p := p
+		r.Get(p, func(ctx context.Context) { ctx.Response().Header().Set("X-Route", p) })
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		route  string // 为空时不应由API路由处理
+	}{
+		{http.MethodGet, "/uploads", "/uploads"},
+		{http.MethodGet, "/wwwfoo", "/wwwfoo"},
+		{http.MethodGet, "/upload", ""},
+		{http.MethodGet, "/upload/avatar", ""},
+		{http.MethodOptions, "/upload/tus", ""},
+		{http.MethodGet, "/www/missing.css", ""},
+	}
+	for _, tt := range tests {
+		rw := serve(r, tt.method, tt.path)
+		if got := rw.Header().Get("X-Route"); got != tt.route {
+			t.Errorf("%s %s: handled by route %q, want %q (status %d)", tt.method, tt.path, got, tt.route, rw.Code)
+		}
+	}
+	if rw := serve(r, http.MethodOptions, "/upload/tus"); rw.Header().Get(tus.Header_Version) != tus.Version {
+		t.Errorf("OPTIONS /upload/tus: status %d, not handled by tus", rw.Code)
+	}
+	// 位于上传路由前缀下，但不在tus路由前缀下
+	if rw := serve(r, http.MethodPost, "/upload/tus-x"); len(rw.Header().Get(tus.Header_Resumable)) > 0 {
+		t.Errorf("POST /upload/tus-x: status %d, handled by tus", rw.Code)
+	}
+	if rw := serve(r, http.MethodGet, "/upload"); rw.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /upload: status %d, want %d", rw.Code, http.StatusMethodNotAllowed)
+	}
+}
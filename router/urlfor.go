@@ -0,0 +1,105 @@
+package router
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+)
+
+// Route 已注册的路由，用于设置路由名称。
+type Route interface {
+	// Name 设置路由名称，名称已被其它路由使用时panic。
+	Name(name string) Route
+}
+
+type namedRoute struct {
+	router *myRouter
+	route  *route
+}
+
+func (nr *namedRoute) Name(name string) Route {
+	names := nr.router.names
+	if exist, ok := names[name]; ok && exist != nr.route {
+		// 控制器路由的默认名称可以被覆盖
+		if exist.name != exist.tag+"."+exist.methodName {
+			panic(genError(fmt.Sprintf("route name %s has registed by %s", name, exist.pattern)))
+		}
+		exist.name = ""
+	}
+	if len(nr.route.name) > 0 {
+		delete(names, nr.route.name)
+	}
+	nr.route.name = name
+	names[name] = nr.route
+	return nr
+}
+
+func (r *myRouter) URLFor(name string, params ...interface{}) (string, error) {
+	route, ok := r.names[name]
+	if !ok {
+		return "", genError(fmt.Sprintf("route %s not found", name))
+	}
+	if len(params)%2 != 0 {
+		return "", genError(fmt.Sprintf("route %s: params must be key-value pairs", name))
+	}
+
+	// 按顺序保存参数，第一个同名参数用于路径参数，其余的作为查询参数
+	pairs := make([][2]string, 0, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		pairs = append(pairs, [2]string{fmt.Sprint(params[i]), fmt.Sprint(params[i+1])})
+	}
+	used := make([]bool, len(pairs))
+	take := func(key string) (string, bool) {
+		for i, pair := range pairs {
+			if !used[i] && pair[0] == key {
+				used[i] = true
+				return pair[1], true
+			}
+		}
+		return "", false
+	}
+
+	segs := splitPath(route.pattern)
+	for i, seg := range segs {
+		switch seg[0] {
+		case ':':
+			key, _, check, _ := parseParam(seg[1:])
+			val, ok := take(key)
+			if !ok {
+				return "", genError(fmt.Sprintf("route %s: missing param %s", name, key))
+			}
+			if check != nil && !check(val) {
+				return "", genError(fmt.Sprintf("route %s: param %s=%q does not match %s", name, key, val, seg))
+			}
+			segs[i] = url.PathEscape(val)
+		case '*':
+			val, _ := take(seg[1:])
+			parts := strings.Split(strings.Trim(val, "/"), "/")
+			for j := range parts {
+				parts[j] = url.PathEscape(parts[j])
+			}
+			segs[i] = strings.Join(parts, "/")
+		}
+	}
+
+	u := "/" + strings.Join(segs, "/")
+	query := url.Values{}
+	for i, pair := range pairs {
+		if !used[i] {
+			query.Add(pair[0], pair[1])
+		}
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u, nil
+}
+
+// FuncMap 模板函数：
+// urlfor 生成命名路由的URL，如 {{urlfor "Account.Login" "next" "/home"}}。
+func (r *myRouter) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"urlfor": r.URLFor,
+	}
+}
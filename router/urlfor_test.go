@@ -0,0 +1,54 @@
+package router
+
+import (
+	"letgo/context"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestURLForRoundTrip(t *testing.T) {
+	r := NewRouterWithOptions(DefaultOptions())
+	var got context.Params
+	capture := func(ctx context.Context) { got = ctx.Params() }
+	r.Get("/api/f/:p", capture).Name("f")
+	r.Get("/api/u/:id:int/files/*path", capture).Name("files")
+
+	tests := []struct {
+		name   string
+		params []interface{}
+		url    string
+		want   context.Params
+	}{
+		{"f", []interface{}{"p", "a/b"}, "/api/f/a%2Fb", context.Params{{Key: "p", Value: "a/b"}}},
+		{"f", []interface{}{"p", "a b?"}, "/api/f/a%20b%3F", context.Params{{Key: "p", Value: "a b?"}}},
+		{"f", []interface{}{"p", "x", "tag", "a", "tag", "b"}, "/api/f/x?tag=a&tag=b", context.Params{{Key: "p", Value: "x"}}},
+		{"f", []interface{}{"p", "x", "p", "y"}, "/api/f/x?p=y", context.Params{{Key: "p", Value: "x"}}},
+		{"files", []interface{}{"id", 7, "path", "x/y z"}, "/api/u/7/files/x/y%20z", context.Params{{Key: "id", Value: "7"}, {Key: "path", Value: "x/y z"}}},
+	}
+	for _, tt := range tests {
+		u, err := r.URLFor(tt.name, tt.params...)
+		if err != nil {
+			t.Fatalf("URLFor %s: %v", tt.name, err)
+		}
+		if u != tt.url {
+			t.Errorf("URLFor %s %v = %s, want %s", tt.name, tt.params, u, tt.url)
+		}
+
+		got = nil
+		rw := httptest.NewRecorder()
+		r.ServeHTTP(rw, httptest.NewRequest("GET", u, nil))
+		if rw.Code != 200 {
+			t.Errorf("GET %s: status %d", u, rw.Code)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("GET %s: params %v, want %v", u, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("GET %s: params %v, want %v", u, got, tt.want)
+			}
+		}
+	}
+}
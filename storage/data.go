@@ -0,0 +1,7 @@
+package storage
+
+const (
+	AdapterName_Local  = "local"
+	AdapterName_Memory = "memory"
+	AdapterName_S3     = "s3"
+)
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var errorPrefix = "storage error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
+
+// ErrNotExist 文件不存在，可以使用errors.Is或os.IsNotExist判断。
+var ErrNotExist = os.ErrNotExist
+
+func notExist(name string) error {
+	return &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+func init() {
+	Register(AdapterName_Local, &LocalStorage{})
+}
+
+// LocalStorage 保存到本地目录的存储，配置：
+//
+//	{"root": "www/upload", "baseURL": "/www/upload"}
+type LocalStorage struct {
+	Root    string `json:"root"`    // 保存文件的根目录
+	BaseURL string `json:"baseURL"` // 访问文件的路由前缀，如静态资源路由下的目录
+}
+
+// NewLocal 创建本地目录的存储。
+func NewLocal(root, baseURL string) Storage {
+	return &LocalStorage{Root: root, BaseURL: baseURL}
+}
+
+func (s *LocalStorage) Open(jsonConfig string) (Storage, error) {
+	local := &LocalStorage{}
+	if len(jsonConfig) > 0 {
+		if err := json.Unmarshal([]byte(jsonConfig), local); err != nil {
+			return nil, genError(fmt.Sprintf("init local storage error: %v", err))
+		}
+	}
+	if len(local.Root) == 0 {
+		return nil, genError("local storage root not set")
+	}
+	return local, nil
+}
+
+func (s *LocalStorage) Put(name string, r io.Reader, size int64, contentType string) error {
+	file, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+
+	// 先写入临时文件再重命名，读取时不会读到写入中的文件
+	tmp, err := ioutil.TempFile(filepath.Dir(file), ".tmp-")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), file)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (s *LocalStorage) Get(name string) (io.ReadCloser, error) {
+	file, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(file)
+}
+
+func (s *LocalStorage) Delete(name string) error {
+	file, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) Stat(name string) (*FileInfo, error) {
+	file, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, notExist(name)
+	}
+	cleaned, _ := cleanName(name)
+	return &FileInfo{
+		Name:        cleaned,
+		Size:        info.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(cleaned)),
+		ModTime:     info.ModTime(),
+	}, nil
+}
+
+func (s *LocalStorage) URL(name string) string {
+	cleaned, _ := cleanName(name)
+	return joinURL(s.BaseURL, cleaned)
+}
+
+// path 文件在本地的路径。
+func (s *LocalStorage) path(name string) (string, error) {
+	cleaned, err := cleanName(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.Root, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register(AdapterName_Memory, &MemoryStorage{})
+}
+
+// MemoryStorage 保存在内存中的存储，用于测试，配置：
+//
+//	{"baseURL": "/files"}
+type MemoryStorage struct {
+	BaseURL string `json:"baseURL"`
+
+	mu    sync.RWMutex
+	files map[string]*memoryFile
+}
+
+type memoryFile struct {
+	data []byte
+	info FileInfo
+}
+
+// NewMemory 创建内存中的存储。
+func NewMemory(baseURL string) Storage {
+	return &MemoryStorage{BaseURL: baseURL, files: make(map[string]*memoryFile)}
+}
+
+func (s *MemoryStorage) Open(jsonConfig string) (Storage, error) {
+	var conf struct {
+		BaseURL string `json:"baseURL"`
+	}
+	if len(jsonConfig) > 0 {
+		if err := json.Unmarshal([]byte(jsonConfig), &conf); err != nil {
+			return nil, genError(fmt.Sprintf("init memory storage error: %v", err))
+		}
+	}
+	return NewMemory(conf.BaseURL), nil
+}
+
+func (s *MemoryStorage) Put(name string, r io.Reader, size int64, contentType string) error {
+	cleaned, err := cleanName(name)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.files[cleaned] = &memoryFile{
+		data: data,
+		info: FileInfo{Name: cleaned, Size: int64(len(data)), ContentType: contentType, ModTime: time.Now()},
+	}
+	return nil
+}
+
+func (s *MemoryStorage) Get(name string) (io.ReadCloser, error) {
+	f, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(f.data)), nil
+}
+
+func (s *MemoryStorage) Delete(name string) error {
+	cleaned, err := cleanName(name)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.files, cleaned)
+	return nil
+}
+
+func (s *MemoryStorage) Stat(name string) (*FileInfo, error) {
+	f, err := s.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	info := f.info
+	return &info, nil
+}
+
+func (s *MemoryStorage) URL(name string) string {
+	cleaned, _ := cleanName(name)
+	return joinURL(s.BaseURL, cleaned)
+}
+
+func (s *MemoryStorage) lookup(name string) (*memoryFile, error) {
+	cleaned, err := cleanName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	f, ok := s.files[cleaned]
+	if !ok {
+		return nil, notExist(cleaned)
+	}
+	return f, nil
+}
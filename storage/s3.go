@@ -0,0 +1,323 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(AdapterName_S3, &S3Storage{})
+}
+
+// S3Storage 兼容S3协议的对象存储，如AWS S3、MinIO，请求使用AWS Signature V4签名，配置：
+//
+//	{"endpoint": "http://127.0.0.1:9000", "region": "us-east-1", "bucket": "upload",
+//	 "accessKey": "minioadmin", "secretKey": "minioadmin", "pathStyle": true}
+type S3Storage struct {
+	Endpoint  string `json:"endpoint"` // 服务地址，如 https://s3.amazonaws.com
+	Region    string `json:"region"`   // 为空时使用us-east-1
+	Bucket    string `json:"bucket"`
+	AccessKey string `json:"accessKey"`
+	SecretKey string `json:"secretKey"`
+	PathStyle bool   `json:"pathStyle"` // 使用 <endpoint>/<bucket>/<name> 格式的地址，MinIO需要开启
+	BaseURL   string `json:"baseURL"`   // 访问文件的地址前缀，如CDN，为空时使用对象的地址
+
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+func (s *S3Storage) Open(jsonConfig string) (Storage, error) {
+	s3 := &S3Storage{}
+	if len(jsonConfig) > 0 {
+		if err := json.Unmarshal([]byte(jsonConfig), s3); err != nil {
+			return nil, genError(fmt.Sprintf("init s3 storage error: %v", err))
+		}
+	}
+	if err := s3.init(); err != nil {
+		return nil, err
+	}
+	return s3, nil
+}
+
+// NewS3 创建S3存储，参数同配置项。
+func NewS3(endpoint, region, bucket, accessKey, secretKey string, pathStyle bool) (Storage, error) {
+	s3 := &S3Storage{
+		Endpoint:  endpoint,
+		Region:    region,
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		SecretKey: secretKey,
+		PathStyle: pathStyle,
+	}
+	if err := s3.init(); err != nil {
+		return nil, err
+	}
+	return s3, nil
+}
+
+func (s *S3Storage) init() error {
+	if len(s.Endpoint) == 0 || len(s.Bucket) == 0 {
+		return genError("s3 storage endpoint or bucket not set")
+	}
+	u, err := url.Parse(s.Endpoint)
+	if err != nil || len(u.Host) == 0 {
+		return genError(fmt.Sprintf("invalid s3 endpoint %q", s.Endpoint))
+	}
+	if len(s.Region) == 0 {
+		s.Region = "us-east-1"
+	}
+	s.endpoint = u
+	s.client = &http.Client{Timeout: 5 * time.Minute}
+	s.now = time.Now
+	return nil
+}
+
+func (s *S3Storage) Put(name string, r io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(http.MethodPut, name, r)
+	if err != nil {
+		return err
+	}
+	if size < 0 {
+		// S3不支持分块传输编码，未知大小时先读取全部内容
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(data))
+		size = int64(len(data))
+	}
+	req.ContentLength = size
+	if len(contentType) > 0 {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Get(name string) (io.ReadCloser, error) {
+	req, err := s.newRequest(http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(name string) error {
+	req, err := s.newRequest(http.MethodDelete, name, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err != nil && !isNotExist(err) {
+		return err
+	}
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return nil
+}
+
+func (s *S3Storage) Stat(name string) (*FileInfo, error) {
+	req, err := s.newRequest(http.MethodHead, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	cleaned, _ := cleanName(name)
+	info := &FileInfo{
+		Name:        cleaned,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info, nil
+}
+
+func (s *S3Storage) URL(name string) string {
+	cleaned, _ := cleanName(name)
+	if len(s.BaseURL) > 0 {
+		return joinURL(s.BaseURL, cleaned)
+	}
+	return s.objectURL(cleaned).String()
+}
+
+// objectURL 对象的地址，PathStyle时为 <endpoint>/<bucket>/<name>，否则为 <bucket>.<endpoint>/<name>。
+func (s *S3Storage) objectURL(name string) *url.URL {
+	u := *s.endpoint
+	prefix := strings.TrimSuffix(u.Path, "/")
+	if s.PathStyle {
+		u.Path = prefix + "/" + s.Bucket + "/" + name
+	} else {
+		u.Host = s.Bucket + "." + u.Host
+		u.Path = prefix + "/" + name
+	}
+	u.RawPath = ""
+	return &u
+}
+
+func (s *S3Storage) newRequest(method, name string, body io.Reader) (*http.Request, error) {
+	cleaned, err := cleanName(name)
+	if err != nil {
+		return nil, err
+	}
+	u := s.objectURL(cleaned)
+	u.RawPath = encodePath(u.Path)
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// do 签名并发送请求，404返回ErrNotExist，其它非2xx状态返回S3的错误信息。
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	s.sign(req, s.now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, notExist(req.URL.Path)
+	}
+	msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, genError(fmt.Sprintf("s3 %s %s: %s %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg))))
+}
+
+const (
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat   = "20060102T150405Z"
+)
+
+// sign 使用AWS Signature V4签名请求，签名Host和所有X-Amz-开头的请求头，
+// 以及请求中已有的Content-Type、Range等请求头。
+func (s *S3Storage) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format(amzDateFormat)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, vals := range req.Header {
+		headers[strings.ToLower(key)] = strings.TrimSpace(strings.Join(vals, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{signAlgorithm, amzDate, scope, hashHex(canonicalRequest)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, s.AccessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, key := range keys {
+		vals := query[key]
+		sort.Strings(vals)
+		for _, val := range vals {
+			parts = append(parts, uriEncode(key, true)+"="+uriEncode(val, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// encodePath 按S3的规则编码路径，保留 /。
+func encodePath(p string) string {
+	return uriEncode(p, false)
+}
+
+// uriEncode 按RFC 3986编码，只保留字母、数字和 -_.~，encodeSlash为false时保留 /。
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteString("%" + strings.ToUpper(strconv.FormatUint(uint64(c)|0x100, 16)[1:]))
+		}
+	}
+	return b.String()
+}
+
+func hashHex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func isNotExist(err error) bool {
+	return errors.Is(err, ErrNotExist)
+}
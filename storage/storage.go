@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Storage 上传文件的存储。文件名为以 / 分隔的相对路径，如 avatar/1.png，
+// 不能指向存储根目录以外。
+type Storage interface {
+	// Open 按json格式的配置创建存储，配置项见各实现。
+	Open(jsonConfig string) (Storage, error)
+
+	// Put 保存文件，已存在时覆盖，size为-1时表示未知。
+	Put(name string, r io.Reader, size int64, contentType string) error
+	// Get 读取文件，文件不存在时返回ErrNotExist。
+	Get(name string) (io.ReadCloser, error)
+	// Delete 删除文件，文件不存在时不返回错误。
+	Delete(name string) error
+	// Stat 获取文件信息，文件不存在时返回ErrNotExist。
+	Stat(name string) (*FileInfo, error)
+	// URL 访问文件的地址。
+	URL(name string) string
+}
+
+// FileInfo 已保存的文件信息。
+type FileInfo struct {
+	Name        string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// New 按配置创建存储。
+// @param adapterName 存储的名字，见AdapterName_Local等；
+// @param jsonConfig json格式的配置。
+func New(adapterName, jsonConfig string) (Storage, error) {
+	adapter, ok := adapters[adapterName]
+	if !ok {
+		return nil, genError(fmt.Sprintf("Unknown adapter name: %s", adapterName))
+	}
+	return adapter.Open(jsonConfig)
+}
+
+// adapters 存储类型的容器。
+var adapters = make(map[string]Storage)
+
+// Register 注册存储类型。
+func Register(adapterName string, adapter Storage) {
+	if adapter == nil {
+		panic(genError("register storage is nil"))
+	}
+	if _, ok := adapters[adapterName]; ok {
+		panic(genError(fmt.Sprintf("%s has registed", adapterName)))
+	}
+	adapters[adapterName] = adapter
+}
+
+// cleanName 清理文件名，不能为空或指向根目录以外。
+func cleanName(name string) (string, error) {
+	cleaned := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+	if len(cleaned) == 0 {
+		return "", genError(fmt.Sprintf("invalid file name %q", name))
+	}
+	return cleaned, nil
+}
+
+// joinURL 拼接访问地址的前缀和文件名。
+func joinURL(baseURL, name string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + name
+}
@@ -0,0 +1,36 @@
+package tus
+
+import "time"
+
+const (
+	Version = "1.0.0"
+
+	// 支持的扩展
+	Extensions = "creation,termination,expiration,checksum"
+	// 支持的校验算法
+	ChecksumAlgorithms = "sha1,sha256,md5"
+
+	Header_Resumable         = "Tus-Resumable"
+	Header_Version           = "Tus-Version"
+	Header_Extension         = "Tus-Extension"
+	Header_MaxSize           = "Tus-Max-Size"
+	Header_ChecksumAlgorithm = "Tus-Checksum-Algorithm"
+	Header_UploadOffset      = "Upload-Offset"
+	Header_UploadLength      = "Upload-Length"
+	Header_UploadMetadata    = "Upload-Metadata"
+	Header_UploadExpires     = "Upload-Expires"
+	Header_UploadChecksum    = "Upload-Checksum"
+	// Header_UploadFile 上传完成后保存的文件地址，扩展的响应头
+	Header_UploadFile = "Upload-File"
+
+	MIME_OffsetOctetStream = "application/offset+octet-stream"
+
+	// StatusChecksumMismatch 校验和不匹配，tus协议定义的状态码。
+	StatusChecksumMismatch = 460
+)
+
+// 默认配置，见Options。
+const (
+	Default_Expiration      = 24 * time.Hour
+	Default_CleanupInterval = time.Hour
+)
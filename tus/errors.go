@@ -0,0 +1,41 @@
+package tus
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var errorPrefix = "tus error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
+
+// StatusError 带HTTP状态码的错误，见Options.ErrorHandler。
+type StatusError interface {
+	error
+	StatusCode() int
+}
+
+// Error 请求不符合协议或上传的状态时返回的错误，Status为对应的HTTP状态码。
+type Error struct {
+	Status  int
+	Message string
+}
+
+func newError(status int, format string, args ...interface{}) *Error {
+	return &Error{Status: status, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// StatusCode 错误对应的HTTP状态码。
+func (e *Error) StatusCode() int {
+	if e.Status == 0 {
+		return http.StatusBadRequest
+	}
+	return e.Status
+}
@@ -0,0 +1,586 @@
+package tus
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"letgo/log"
+	"letgo/upload"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handler 实现tus 1.0可续传上传协议的核心部分及creation、termination、
+// expiration、checksum扩展，见 https://tus.io/protocols/resumable-upload。
+// 上传中的文件保存在Options.Folder，上传完成后由Options.Uploader检查并保存，
+// 与multipart上传的文件使用相同的限制。
+// 完成上传的PATCH响应200，内容为JSON格式的upload.Result，与multipart上传的响应相同；
+// 已完成的上传在HEAD和PATCH响应的Upload-File头中返回文件的地址。
+type Handler interface {
+	http.Handler
+
+	// Cleanup 删除已过期的上传，返回删除的数量。
+	Cleanup() (int, error)
+	// StartCleanup 按间隔定时执行Cleanup，调用返回的函数停止。
+	StartCleanup(interval time.Duration) (stop func())
+}
+
+// Options 配置。
+type Options struct {
+	BasePath   string          // 路由，如 /files，上传的地址为 <BasePath>/<id>
+	Folder     string          // 保存上传中的文件的目录
+	Uploader   upload.Uploader // 上传完成后检查并保存文件，见upload.Uploader.SaveFile
+	Dir        string          // 在存储中保存文件的目录
+	MaxSize    int64           // 上传文件的最大字节数，不大于0时不限制，不应超过Uploader的限制
+	Expiration time.Duration   // 上传在最后一次写入后的有效期，0时使用Default_Expiration
+	Logger     log.Logger      // 记录内部错误的日志，为空时使用log.Log
+
+	// OnComplete 上传完成并保存到存储后调用。
+	OnComplete func(upload *Upload)
+	// ErrorHandler 输出错误响应，如与路由的其它错误使用相同的JSON格式，为空时输出纯文本。
+	// err为*Error，或上传完成后文件不符合要求时的*upload.Error。
+	ErrorHandler func(rw http.ResponseWriter, req *http.Request, err StatusError)
+}
+
+// Upload 上传的信息，保存为上传目录中的 <id>.json。
+type Upload struct {
+	ID       string            `json:"id"`
+	Length   int64             `json:"length"`
+	Offset   int64             `json:"offset"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Expires  time.Time         `json:"expires"`
+
+	// 上传完成后保存到存储的文件
+	File *upload.File `json:"file,omitempty"`
+}
+
+// Completed 是否已上传完成。
+func (u *Upload) Completed() bool {
+	return u.Offset == u.Length
+}
+
+type myHandler struct {
+	options Options
+
+	mu     sync.Mutex
+	locked map[string]bool // 正在写入的上传
+}
+
+// New 创建tus上传处理。
+func New(opts Options) Handler {
+	if opts.Expiration == 0 {
+		opts.Expiration = Default_Expiration
+	}
+	opts.BasePath = "/" + strings.Trim(opts.BasePath, "/")
+	return &myHandler{options: opts, locked: make(map[string]bool)}
+}
+
+func (h *myHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	header := rw.Header()
+	header.Set(Header_Resumable, Version)
+
+	if req.Method == http.MethodOptions {
+		header.Set(Header_Version, Version)
+		header.Set(Header_Extension, Extensions)
+		header.Set(Header_ChecksumAlgorithm, ChecksumAlgorithms)
+		if h.options.MaxSize > 0 {
+			header.Set(Header_MaxSize, strconv.FormatInt(h.options.MaxSize, 10))
+		}
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if req.Header.Get(Header_Resumable) != Version {
+		header.Set(Header_Version, Version)
+		h.writeError(rw, req, newError(http.StatusPreconditionFailed, "unsupported tus version"))
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(req.URL.Path, h.options.BasePath), "/")
+	switch {
+	case len(id) == 0 && req.Method == http.MethodPost:
+		h.create(rw, req)
+	case len(id) == 0:
+		rw.Header().Set("Allow", "OPTIONS, POST")
+		h.writeError(rw, req, newError(http.StatusMethodNotAllowed, ""))
+	case !validID.MatchString(id):
+		h.writeError(rw, req, newError(http.StatusNotFound, "upload not found"))
+	case req.Method == http.MethodHead:
+		h.head(rw, req, id)
+	case req.Method == http.MethodPatch:
+		h.patch(rw, req, id)
+	case req.Method == http.MethodDelete:
+		h.terminate(rw, req, id)
+	default:
+		rw.Header().Set("Allow", "OPTIONS, HEAD, PATCH, DELETE")
+		h.writeError(rw, req, newError(http.StatusMethodNotAllowed, ""))
+	}
+}
+
+// create 创建上传，返回上传的地址。
+func (h *myHandler) create(rw http.ResponseWriter, req *http.Request) {
+	length, err := strconv.ParseInt(req.Header.Get(Header_UploadLength), 10, 64)
+	if err != nil || length < 0 {
+		h.writeError(rw, req, newError(http.StatusBadRequest, "invalid Upload-Length"))
+		return
+	}
+	if h.options.MaxSize > 0 && length > h.options.MaxSize {
+		h.writeError(rw, req, newError(http.StatusRequestEntityTooLarge, "upload exceeds Tus-Max-Size"))
+		return
+	}
+	metadata, err := parseMetadata(req.Header.Get(Header_UploadMetadata))
+	if err != nil {
+		h.writeError(rw, req, newError(http.StatusBadRequest, "invalid Upload-Metadata"))
+		return
+	}
+
+	id, err := newID()
+	if err != nil {
+		h.fail(rw, req, err)
+		return
+	}
+	upload := &Upload{
+		ID:       id,
+		Length:   length,
+		Metadata: metadata,
+		Expires:  time.Now().Add(h.options.Expiration),
+	}
+	if err := os.MkdirAll(h.options.Folder, 0755); err != nil {
+		h.fail(rw, req, err)
+		return
+	}
+	f, err := os.OpenFile(h.dataFile(id), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		h.fail(rw, req, err)
+		return
+	}
+	f.Close()
+	if err := h.save(upload); err != nil {
+		h.fail(rw, req, err)
+		return
+	}
+
+	// 空文件创建后即完成
+	if length == 0 {
+		if err := h.complete(upload); err != nil {
+			h.rejectOrFail(rw, req, id, err)
+			return
+		}
+		rw.Header().Set(Header_UploadFile, upload.File.URL)
+	}
+
+	rw.Header().Set("Location", h.options.BasePath+"/"+id)
+	rw.Header().Set(Header_UploadExpires, upload.Expires.UTC().Format(http.TimeFormat))
+	rw.WriteHeader(http.StatusCreated)
+}
+
+// head 返回上传的偏移量。
+func (h *myHandler) head(rw http.ResponseWriter, req *http.Request, id string) {
+	upload, ok := h.lookup(rw, req, id)
+	if !ok {
+		return
+	}
+	header := rw.Header()
+	header.Set("Cache-Control", "no-store")
+	header.Set(Header_UploadOffset, strconv.FormatInt(upload.Offset, 10))
+	header.Set(Header_UploadLength, strconv.FormatInt(upload.Length, 10))
+	if len(upload.Metadata) > 0 {
+		header.Set(Header_UploadMetadata, formatMetadata(upload.Metadata))
+	}
+	if !upload.Completed() {
+		header.Set(Header_UploadExpires, upload.Expires.UTC().Format(http.TimeFormat))
+	}
+	if upload.File != nil {
+		header.Set(Header_UploadFile, upload.File.URL)
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// patch 从Upload-Offset开始写入请求体，有Upload-Checksum时校验本次写入的内容。
+func (h *myHandler) patch(rw http.ResponseWriter, req *http.Request, id string) {
+	if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType != MIME_OffsetOctetStream {
+		h.writeError(rw, req, newError(http.StatusUnsupportedMediaType, "Content-Type must be %s", MIME_OffsetOctetStream))
+		return
+	}
+	offset, err := strconv.ParseInt(req.Header.Get(Header_UploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		h.writeError(rw, req, newError(http.StatusBadRequest, "invalid Upload-Offset"))
+		return
+	}
+	checksum, expected, err := parseChecksum(req.Header.Get(Header_UploadChecksum))
+	if err != nil {
+		h.writeError(rw, req, newError(http.StatusBadRequest, "%v", err))
+		return
+	}
+
+	if !h.lock(id) {
+		h.writeError(rw, req, newError(http.StatusLocked, "upload is being written"))
+		return
+	}
+	defer h.unlock(id)
+
+	upload, ok := h.lookup(rw, req, id)
+	if !ok {
+		return
+	}
+	if offset != upload.Offset {
+		h.writeError(rw, req, newError(http.StatusConflict, "Upload-Offset does not match"))
+		return
+	}
+	if req.ContentLength > upload.Length-upload.Offset {
+		h.writeError(rw, req, newError(http.StatusRequestEntityTooLarge, "upload exceeds Upload-Length"))
+		return
+	}
+
+	f, err := os.OpenFile(h.dataFile(id), os.O_WRONLY, 0644)
+	if err != nil {
+		h.fail(rw, req, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		h.fail(rw, req, err)
+		return
+	}
+
+	var w io.Writer = f
+	if checksum != nil {
+		w = io.MultiWriter(f, checksum)
+	}
+	// 连接中断时保留已写入的部分，客户端可以从新的偏移量继续
+	n, copyErr := io.Copy(w, io.LimitReader(req.Body, upload.Length-upload.Offset))
+
+	if checksum != nil && (copyErr != nil || string(checksum.Sum(nil)) != string(expected)) {
+		// 校验失败时丢弃本次写入的内容
+		f.Truncate(offset)
+		rw.Header().Set(Header_UploadOffset, strconv.FormatInt(upload.Offset, 10))
+		if copyErr != nil {
+			h.writeError(rw, req, newError(http.StatusInternalServerError, "write interrupted"))
+			return
+		}
+		h.writeError(rw, req, newError(StatusChecksumMismatch, "checksum mismatch"))
+		return
+	}
+
+	upload.Offset += n
+	upload.Expires = time.Now().Add(h.options.Expiration)
+	if err := h.save(upload); err != nil {
+		h.fail(rw, req, err)
+		return
+	}
+	if copyErr != nil {
+		// 已写入的部分保留，Upload-Offset告知客户端继续的位置
+		rw.Header().Set(Header_UploadOffset, strconv.FormatInt(upload.Offset, 10))
+		h.writeError(rw, req, newError(http.StatusInternalServerError, "write interrupted"))
+		return
+	}
+	rw.Header().Set(Header_UploadOffset, strconv.FormatInt(upload.Offset, 10))
+	if !upload.Completed() {
+		rw.Header().Set(Header_UploadExpires, upload.Expires.UTC().Format(http.TimeFormat))
+		rw.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	f.Close()
+	if err := h.complete(upload); err != nil {
+		h.rejectOrFail(rw, req, id, err)
+		return
+	}
+	h.writeResult(rw, req, upload)
+}
+
+// writeResult 输出已完成的上传保存的文件。
+func (h *myHandler) writeResult(rw http.ResponseWriter, req *http.Request, info *Upload) {
+	body, err := json.Marshal(&upload.Result{Files: []*upload.File{info.File}})
+	if err != nil {
+		h.fail(rw, req, err)
+		return
+	}
+	header := rw.Header()
+	header.Set(Header_UploadFile, info.File.URL)
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(append(body, '\n'))
+}
+
+// terminate 删除上传，已完成的上传只删除上传信息，不删除存储中的文件。
+func (h *myHandler) terminate(rw http.ResponseWriter, req *http.Request, id string) {
+	if !h.lock(id) {
+		h.writeError(rw, req, newError(http.StatusLocked, "upload is being written"))
+		return
+	}
+	defer h.unlock(id)
+
+	if _, ok := h.lookup(rw, req, id); !ok {
+		return
+	}
+	h.remove(id)
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// complete 将上传完成的文件交给Uploader检查并保存，文件名为Upload-Metadata中的filename，
+// 文件不符合要求时返回*upload.Error。
+func (h *myHandler) complete(upload *Upload) error {
+	if h.options.Uploader == nil {
+		return genError("uploader not set")
+	}
+	f, err := os.Open(h.dataFile(upload.ID))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	file, err := h.options.Uploader.SaveFile(f, upload.Metadata["filename"], h.options.Dir)
+	if err != nil {
+		return err
+	}
+	upload.File = file
+	if err := h.save(upload); err != nil {
+		return err
+	}
+	f.Close()
+	os.Remove(h.dataFile(upload.ID))
+
+	if h.options.OnComplete != nil {
+		h.options.OnComplete(upload)
+	}
+	return nil
+}
+
+// rejectOrFail 处理complete的错误，文件不符合要求时删除上传并输出对应的状态码，
+// 客户端无法通过续传修正内容。
+func (h *myHandler) rejectOrFail(rw http.ResponseWriter, req *http.Request, id string, err error) {
+	if e, ok := err.(*upload.Error); ok {
+		h.remove(id)
+		h.writeError(rw, req, e)
+		return
+	}
+	h.fail(rw, req, err)
+}
+
+// lookup 读取上传信息，不存在或已过期时输出错误响应。
+func (h *myHandler) lookup(rw http.ResponseWriter, req *http.Request, id string) (*Upload, bool) {
+	upload, err := h.load(id)
+	if os.IsNotExist(err) {
+		h.writeError(rw, req, newError(http.StatusNotFound, "upload not found"))
+		return nil, false
+	}
+	if err != nil {
+		h.fail(rw, req, err)
+		return nil, false
+	}
+	if !upload.Completed() && time.Now().After(upload.Expires) {
+		h.writeError(rw, req, newError(http.StatusGone, "upload expired"))
+		return nil, false
+	}
+	return upload, true
+}
+
+func (h *myHandler) Cleanup() (int, error) {
+	files, err := filepath.Glob(filepath.Join(h.options.Folder, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	now := time.Now()
+	for _, file := range files {
+		id := strings.TrimSuffix(filepath.Base(file), ".json")
+		if !h.lock(id) {
+			continue
+		}
+		upload, err := h.load(id)
+		if err == nil && now.After(upload.Expires) {
+			h.remove(id)
+			removed++
+		}
+		h.unlock(id)
+	}
+	return removed, nil
+}
+
+func (h *myHandler) StartCleanup(interval time.Duration) (stop func()) {
+	if interval <= 0 {
+		interval = Default_CleanupInterval
+	}
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				h.Cleanup()
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+func (h *myHandler) load(id string) (*Upload, error) {
+	data, err := ioutil.ReadFile(h.infoFile(id))
+	if err != nil {
+		return nil, err
+	}
+	upload := &Upload{}
+	if err := json.Unmarshal(data, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// save 保存上传信息，先写入临时文件再重命名，避免读取到不完整的内容。
+func (h *myHandler) save(upload *Upload) error {
+	data, err := json.Marshal(upload)
+	if err != nil {
+		return err
+	}
+	tmp := h.infoFile(upload.ID) + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.infoFile(upload.ID))
+}
+
+func (h *myHandler) remove(id string) {
+	os.Remove(h.dataFile(id))
+	os.Remove(h.infoFile(id))
+}
+
+func (h *myHandler) dataFile(id string) string {
+	return filepath.Join(h.options.Folder, id+".bin")
+}
+
+func (h *myHandler) infoFile(id string) string {
+	return filepath.Join(h.options.Folder, id+".json")
+}
+
+func (h *myHandler) lock(id string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.locked[id] {
+		return false
+	}
+	h.locked[id] = true
+	return true
+}
+
+func (h *myHandler) unlock(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.locked, id)
+}
+
+// fail 记录内部错误，响应500。
+func (h *myHandler) fail(rw http.ResponseWriter, req *http.Request, err error) {
+	logger := h.options.Logger
+	if logger == nil {
+		logger = log.Log
+	}
+	if logger != nil {
+		logger.Error("%s: %v", errorPrefix, err)
+	}
+	h.writeError(rw, req, newError(http.StatusInternalServerError, ""))
+}
+
+// writeError 输出错误响应，配置了Options.ErrorHandler时由其处理，否则输出纯文本。
+func (h *myHandler) writeError(rw http.ResponseWriter, req *http.Request, err StatusError) {
+	if h.options.ErrorHandler != nil {
+		h.options.ErrorHandler(rw, req, err)
+		return
+	}
+	msg := err.Error()
+	if len(msg) == 0 {
+		msg = http.StatusText(err.StatusCode())
+	}
+	http.Error(rw, msg, err.StatusCode())
+}
+
+var validID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// parseMetadata 解析Upload-Metadata，格式为逗号分隔的 key base64(value)，值可以为空。
+func parseMetadata(header string) (map[string]string, error) {
+	if len(strings.TrimSpace(header)) == 0 {
+		return nil, nil
+	}
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		fields := strings.Fields(pair)
+		if len(fields) == 0 || len(fields) > 2 {
+			return nil, genError(fmt.Sprintf("invalid metadata %q", pair))
+		}
+		var value []byte
+		if len(fields) == 2 {
+			var err error
+			if value, err = base64.StdEncoding.DecodeString(fields[1]); err != nil {
+				return nil, err
+			}
+		}
+		metadata[fields[0]] = string(value)
+	}
+	return metadata, nil
+}
+
+func formatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		if len(value) == 0 {
+			pairs = append(pairs, key)
+			continue
+		}
+		pairs = append(pairs, key+" "+base64.StdEncoding.EncodeToString([]byte(value)))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// parseChecksum 解析Upload-Checksum，格式为 <算法> base64(校验和)，为空时返回nil。
+func parseChecksum(header string) (hash.Hash, []byte, error) {
+	if len(header) == 0 {
+		return nil, nil, nil
+	}
+	fields := strings.Fields(header)
+	if len(fields) != 2 {
+		return nil, nil, genError("invalid Upload-Checksum")
+	}
+	expected, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, nil, genError("invalid Upload-Checksum")
+	}
+	switch fields[0] {
+	case "sha1":
+		return sha1.New(), expected, nil
+	case "sha256":
+		return sha256.New(), expected, nil
+	case "md5":
+		return md5.New(), expected, nil
+	}
+	return nil, nil, genError(fmt.Sprintf("unsupported checksum algorithm %s", fields[0]))
+}
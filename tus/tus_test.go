@@ -0,0 +1,234 @@
+package tus
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"letgo/storage"
+	"letgo/upload"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestHandler(t *testing.T, expiration time.Duration) (Handler, string) {
+	t.Helper()
+	folder := t.TempDir()
+	h := New(Options{
+		BasePath:   "/files",
+		Folder:     folder,
+		Uploader:   upload.New(upload.Options{Storage: storage.NewMemory("/f")}),
+		MaxSize:    1 << 20,
+		Expiration: expiration,
+	})
+	return h, folder
+}
+
+// do 发送tus请求，headers为成对的请求头和值。
+func do(h Handler, method, target string, body io.Reader, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set(Header_Resumable, Version)
+	if method == http.MethodPatch {
+		req.Header.Set("Content-Type", MIME_OffsetOctetStream)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rw := httptest.NewRecorder()
+	h.ServeHTTP(rw, req)
+	return rw
+}
+
+func create(t *testing.T, h Handler, length int, filename string) string {
+	t.Helper()
+	rw := do(h, http.MethodPost, "/files", nil,
+		Header_UploadLength, strconv.Itoa(length),
+		Header_UploadMetadata, "filename "+base64.StdEncoding.EncodeToString([]byte(filename)))
+	if rw.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, %s", rw.Code, rw.Body)
+	}
+	location := rw.Header().Get("Location")
+	if !strings.HasPrefix(location, "/files/") || len(rw.Header().Get(Header_UploadExpires)) == 0 {
+		t.Fatalf("create: Location %q, Upload-Expires %q", location, rw.Header().Get(Header_UploadExpires))
+	}
+	return location
+}
+
+func offsetOf(t *testing.T, h Handler, location string) string {
+	t.Helper()
+	rw := do(h, http.MethodHead, location, nil)
+	if rw.Code != http.StatusOK {
+		t.Fatalf("HEAD %s: status %d", location, rw.Code)
+	}
+	return rw.Header().Get(Header_UploadOffset)
+}
+
+func TestProtocol(t *testing.T) {
+	h, _ := newTestHandler(t, 0)
+	data := []byte("hello resumable upload")
+
+	rw := do(h, http.MethodOptions, "/files", nil)
+	if rw.Code != http.StatusNoContent || rw.Header().Get(Header_Version) != Version ||
+		rw.Header().Get(Header_Extension) != Extensions || rw.Header().Get(Header_MaxSize) != "1048576" {
+		t.Errorf("OPTIONS: status %d, headers %v", rw.Code, rw.Header())
+	}
+	if rw := do(h, http.MethodPost, "/files", nil, Header_Resumable, "0.2.0"); rw.Code != http.StatusPreconditionFailed {
+		t.Errorf("unsupported version: status %d, want %d", rw.Code, http.StatusPreconditionFailed)
+	}
+	if rw := do(h, http.MethodPost, "/files", nil, Header_UploadLength, "2000000"); rw.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("create over Tus-Max-Size: status %d, want %d", rw.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	location := create(t, h, len(data), "a.txt")
+	if got := offsetOf(t, h, location); got != "0" {
+		t.Errorf("HEAD after create: offset %s, want 0", got)
+	}
+
+	rw = do(h, http.MethodPatch, location, bytes.NewReader(data[:5]), Header_UploadOffset, "0")
+	if rw.Code != http.StatusNoContent || rw.Header().Get(Header_UploadOffset) != "5" {
+		t.Fatalf("PATCH first chunk: status %d, offset %q", rw.Code, rw.Header().Get(Header_UploadOffset))
+	}
+	if got := offsetOf(t, h, location); got != "5" {
+		t.Errorf("HEAD after first chunk: offset %s, want 5", got)
+	}
+
+	// 偏移量与已上传的部分不一致
+	rw = do(h, http.MethodPatch, location, bytes.NewReader(data[3:]), Header_UploadOffset, "3")
+	if rw.Code != http.StatusConflict {
+		t.Errorf("PATCH wrong offset: status %d, want %d", rw.Code, http.StatusConflict)
+	}
+	rw = do(h, http.MethodPatch, location, bytes.NewReader(data[5:]), Header_UploadOffset, "5", "Content-Type", "text/plain")
+	if rw.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("PATCH wrong Content-Type: status %d, want %d", rw.Code, http.StatusUnsupportedMediaType)
+	}
+
+	// 完成上传的响应包含保存的文件
+	rw = do(h, http.MethodPatch, location, bytes.NewReader(data[5:]), Header_UploadOffset, "5")
+	var result upload.Result
+	if err := json.Unmarshal(rw.Body.Bytes(), &result); rw.Code != http.StatusOK || err != nil || len(result.Files) != 1 {
+		t.Fatalf("PATCH last chunk: status %d, %s", rw.Code, rw.Body)
+	}
+	file := result.Files[0]
+	if file.Size != int64(len(data)) || file.Filename != "a.txt" || rw.Header().Get(Header_UploadFile) != file.URL {
+		t.Errorf("completed file %+v, Upload-File %q", file, rw.Header().Get(Header_UploadFile))
+	}
+	rw = do(h, http.MethodHead, location, nil)
+	if rw.Header().Get(Header_UploadOffset) != strconv.Itoa(len(data)) || rw.Header().Get(Header_UploadFile) != file.URL {
+		t.Errorf("HEAD completed: headers %v", rw.Header())
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	h, _ := newTestHandler(t, 0)
+	data := []byte("checksum")
+	location := create(t, h, len(data), "c.txt")
+
+	sum := func(b []byte) string {
+		s := sha1.Sum(b)
+		return "sha1 " + base64.StdEncoding.EncodeToString(s[:])
+	}
+	rw := do(h, http.MethodPatch, location, bytes.NewReader(data), Header_UploadOffset, "0", Header_UploadChecksum, sum([]byte("other")))
+	if rw.Code != StatusChecksumMismatch || rw.Header().Get(Header_UploadOffset) != "0" {
+		t.Errorf("checksum mismatch: status %d, offset %q, want %d and 0", rw.Code, rw.Header().Get(Header_UploadOffset), StatusChecksumMismatch)
+	}
+	if got := offsetOf(t, h, location); got != "0" {
+		t.Errorf("HEAD after mismatch: offset %s, want 0", got)
+	}
+	rw = do(h, http.MethodPatch, location, bytes.NewReader(data), Header_UploadOffset, "0", Header_UploadChecksum, "crc32 AAAA")
+	if rw.Code != http.StatusBadRequest {
+		t.Errorf("unsupported algorithm: status %d, want %d", rw.Code, http.StatusBadRequest)
+	}
+	rw = do(h, http.MethodPatch, location, bytes.NewReader(data), Header_UploadOffset, "0", Header_UploadChecksum, sum(data))
+	if rw.Code != http.StatusOK {
+		t.Errorf("checksum match: status %d, %s", rw.Code, rw.Body)
+	}
+}
+
+// failingReader 读取部分内容后出错，模拟连接中断。
+type failingReader struct {
+	data []byte
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errors.New("connection reset")
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestInterruptedWrite(t *testing.T) {
+	h, _ := newTestHandler(t, 0)
+	location := create(t, h, 10, "i.txt")
+
+	// 已写入的部分保留，响应中返回继续的偏移量
+	rw := do(h, http.MethodPatch, location, &failingReader{[]byte("abcd")}, Header_UploadOffset, "0")
+	if rw.Code != http.StatusInternalServerError || rw.Header().Get(Header_UploadOffset) != "4" {
+		t.Errorf("interrupted: status %d, offset %q, want 500 and 4", rw.Code, rw.Header().Get(Header_UploadOffset))
+	}
+	if got := offsetOf(t, h, location); got != "4" {
+		t.Errorf("HEAD after interrupted write: offset %s, want 4", got)
+	}
+
+	// 带校验和时丢弃本次写入的部分
+	rw = do(h, http.MethodPatch, location, &failingReader{[]byte("ef")}, Header_UploadOffset, "4", Header_UploadChecksum, "sha1 AAAA")
+	if rw.Code != http.StatusInternalServerError || rw.Header().Get(Header_UploadOffset) != "4" {
+		t.Errorf("interrupted with checksum: status %d, offset %q, want 500 and 4", rw.Code, rw.Header().Get(Header_UploadOffset))
+	}
+}
+
+func TestTerminate(t *testing.T) {
+	h, folder := newTestHandler(t, 0)
+	location := create(t, h, 10, "t.txt")
+
+	if rw := do(h, http.MethodDelete, location, nil); rw.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status %d, want %d", rw.Code, http.StatusNoContent)
+	}
+	if rw := do(h, http.MethodHead, location, nil); rw.Code != http.StatusNotFound {
+		t.Errorf("HEAD after DELETE: status %d, want %d", rw.Code, http.StatusNotFound)
+	}
+	if files, _ := filepath.Glob(filepath.Join(folder, "*")); len(files) != 0 {
+		t.Errorf("files left after DELETE: %v", files)
+	}
+}
+
+func TestExpiration(t *testing.T) {
+	h, folder := newTestHandler(t, 10*time.Millisecond)
+	expired := create(t, h, 10, "e.txt")
+	time.Sleep(20 * time.Millisecond)
+	active := create(t, h, 10, "a.txt")
+
+	if rw := do(h, http.MethodHead, expired, nil); rw.Code != http.StatusGone {
+		t.Errorf("HEAD expired: status %d, want %d", rw.Code, http.StatusGone)
+	}
+	rw := do(h, http.MethodPatch, expired, strings.NewReader("x"), Header_UploadOffset, "0")
+	if rw.Code != http.StatusGone {
+		t.Errorf("PATCH expired: status %d, want %d", rw.Code, http.StatusGone)
+	}
+
+	// 只清理过期的上传，未过期的上传保留
+	removed, err := h.Cleanup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("Cleanup removed %d, want 1", removed)
+	}
+	if rw := do(h, http.MethodHead, expired, nil); rw.Code != http.StatusNotFound {
+		t.Errorf("HEAD after Cleanup: status %d, want %d", rw.Code, http.StatusNotFound)
+	}
+	if got := offsetOf(t, h, active); got != "0" {
+		t.Errorf("active upload after Cleanup: offset %s", got)
+	}
+	if files, _ := filepath.Glob(filepath.Join(folder, "*.bin")); len(files) != 1 {
+		t.Errorf("data files after Cleanup: %v, want only the active upload", files)
+	}
+}
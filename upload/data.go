@@ -0,0 +1,19 @@
+package upload
+
+// 上传的默认限制，见Options。
+const (
+	Max_Size      = 32 << 20
+	Max_Files     = 10
+	Max_Fields    = 100
+	Max_FieldSize = 1 << 20 // 非文件字段的总字节数
+	Max_Overhead  = 1 << 20 // 默认的请求体大小中为分隔符和各部分的头预留的字节数
+)
+
+// 文件的命名方式，见Options.Naming。
+const (
+	Naming_Hash   = "hash"   // 按内容的SHA-256命名，相同内容只保存一次
+	Naming_Random = "random" // 随机命名
+)
+
+// Index_Extension 内容索引中记录的扩展名，见Options.Index。
+const Index_Extension = ".json"
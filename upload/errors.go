@@ -0,0 +1,39 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var errorPrefix = "upload error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
+
+// Error 上传请求不符合要求时返回的错误，Status为对应的HTTP状态码。
+type Error struct {
+	Status  int
+	Field   string // 出错的表单字段
+	Message string
+}
+
+func newError(status int, field, format string, args ...interface{}) *Error {
+	return &Error{Status: status, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *Error) Error() string {
+	if len(e.Field) > 0 {
+		return fmt.Sprintf("%s: %s", e.Field, e.Message)
+	}
+	return e.Message
+}
+
+// StatusCode 错误对应的HTTP状态码。
+func (e *Error) StatusCode() int {
+	if e.Status == 0 {
+		return http.StatusBadRequest
+	}
+	return e.Status
+}
@@ -0,0 +1,660 @@
+package upload
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"image"
+	"io"
+	"io/ioutil"
+	"letgo/imaging"
+	"letgo/storage"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Uploader 处理multipart/form-data的上传请求，将文件保存到Options.Storage。
+//
+// 路由的上传前缀对所有目录使用同一配置，需要不同限制的路由可以创建各自的Uploader，
+// 在处理函数中调用Save，如只接收一张1MB以内图片的头像上传：
+//
+//	avatars := upload.New(upload.Options{
+//		Storage:      store,
+//		MaxSize:      1 << 20,
+//		MaxFiles:     1,
+//		AllowedTypes: []string{"image/*"},
+//	})
+//	r.Post("/api/avatar", func(ctx context.Context) {
+//		result, err := avatars.Save(ctx.Request(), "avatar")
+//		...
+//	})
+type Uploader interface {
+	// Save 读取请求中的文件和表单字段，文件保存到存储的dir目录下，
+	// dir中的 .. 等不能指向存储根目录以外。请求不符合要求时返回*Error，
+	// 出错时已保存的文件会被删除。
+	Save(req *http.Request, dir string) (*Result, error)
+	// SaveFile 保存不是通过multipart上传的文件，如tus上传完成的文件，
+	// 与Save中的文件做相同的检查和处理：大小、扩展名、内容类型、命名、去重及变体。
+	// filename为客户端提供的文件名，用于检查扩展名，文件不符合要求时返回*Error。
+	SaveFile(r io.Reader, filename, dir string) (*File, error)
+	// Remove 删除Save保存的文件及其变体，用于保存后无法输出结果等情况，
+	// 不删除已存在的相同内容的文件。
+	Remove(result *Result)
+	// Lookup 按内容的SHA-256（十六进制）查找已保存的文件，
+	// 未找到或未配置Options.Index时返回storage.ErrNotExist。
+	Lookup(hash string) (*Entry, error)
+}
+
+// Options 上传配置。
+type Options struct {
+	Storage      storage.Storage // 保存上传文件的存储
+	TempDir      string          // 检查文件时使用的临时目录，为空时使用系统的临时目录
+	MaxSize      int64           // 单个文件的最大字节数，0时使用Max_Size，小于0时不限制
+	MaxFiles     int             // 每次上传的最大文件数，0时使用Max_Files，小于0时不限制
+	MaxFields    int             // 每次上传的最大非文件字段数，0时使用Max_Fields，小于0时不限制
+	MaxBodySize  int64           // 请求体的最大字节数，0时按MaxSize和MaxFiles计算，见New，小于0时不限制
+	Fields       []string        // 接收文件的表单字段，为空时接收所有字段
+	AllowedExts  []string        // 允许的扩展名，如 .jpg，为空时不限制
+	AllowedTypes []string        // 允许的MIME类型，按文件内容检测，支持 image/* 的写法，为空时不限制
+
+	// 无论是否配置AllowedExts和AllowedTypes，扩展名都必须与检测的内容类型相符，见matchExt，
+	// 避免如带PNG文件头的 evil.html 以text/html从存储输出。HTML、SVG、脚本等浏览器会执行的类型
+	// 只有在AllowedTypes（不含 text/* 等通配）或AllowedExts中明确列出时才接受。
+
+	// Naming 文件的命名方式，为空时使用Naming_Hash。按内容命名时，
+	// 相同内容的文件已存在则不再保存，返回已有的文件。
+	Naming string
+	// DateShard 按上传日期分目录的时间格式，如 2006/01/02，为空时不分目录。
+	DateShard string
+	// Index 保存内容索引的存储，记录内容对应的文件和上传的文件名，用于Lookup
+	// 及跨目录、跨日期的去重，为空时只按文件名去重。索引包含原始文件名，
+	// 不应使用可以公开访问的存储。
+	Index storage.Storage
+
+	// Variants 上传PNG、JPEG、GIF图片后生成的变体，如缩略图，保存在原图的同一目录，
+	// 如 a.jpg 的变体thumb保存为 a_thumb.jpg。变体重新编码，不包含EXIF等元数据，
+	// 原图保留上传的内容，见StripMetadata。
+	Variants []imaging.Variant
+	// StripMetadata 上传的JPEG、PNG图片重新编码后保存，去除EXIF（包括GPS位置）等元数据，
+	// JPEG按EXIF中的方向旋转，质量为imaging.Default_Quality。GIF不处理，以保留动画。
+	// 按内容命名时使用重新编码后的内容。
+	StripMetadata bool
+	// MaxPixels 生成变体或去除元数据时图片的最大像素数，0时使用imaging.Max_Pixels。
+	MaxPixels int
+}
+
+// Result 上传的结果。
+type Result struct {
+	Files  []*File             `json:"files"`
+	Fields map[string][]string `json:"fields,omitempty"` // 非文件的表单字段
+}
+
+// File 已保存的文件。
+type File struct {
+	Field       string `json:"field"`    // 表单字段
+	Filename    string `json:"filename"` // 上传的文件名
+	Name        string `json:"name"`     // 在存储中的文件名
+	URL         string `json:"url,omitempty"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`         // 按文件内容检测的MIME类型
+	Hash        string `json:"hash,omitempty"`      // 内容的SHA-256，按内容命名时设置
+	Duplicate   bool   `json:"duplicate,omitempty"` // 相同内容的文件已存在，未重新保存
+
+	// 图片的宽高及生成的变体，见Options.Variants
+	Width    int               `json:"width,omitempty"`
+	Height   int               `json:"height,omitempty"`
+	Variants map[string]*Image `json:"variants,omitempty"`
+}
+
+// Image 图片的变体。
+type Image struct {
+	Name        string `json:"name"` // 在存储中的文件名
+	URL         string `json:"url,omitempty"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"contentType"`
+}
+
+// Entry 内容索引中的记录。
+type Entry struct {
+	Hash        string    `json:"hash"`
+	Name        string    `json:"name"` // 在存储中的文件名
+	URL         string    `json:"url,omitempty"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"contentType"`
+	Filenames   []string  `json:"filenames"` // 上传时使用过的文件名
+	Created     time.Time `json:"created"`
+}
+
+type myUploader struct {
+	options Options
+
+	mu sync.Mutex // 更新内容索引
+}
+
+// New 创建上传处理。MaxBodySize为0时使用 MaxSize*MaxFiles+Max_FieldSize+Max_Overhead，
+// MaxSize或MaxFiles不限制时请求体也不限制。
+func New(opts Options) Uploader {
+	if opts.MaxSize == 0 {
+		opts.MaxSize = Max_Size
+	}
+	if opts.MaxFiles == 0 {
+		opts.MaxFiles = Max_Files
+	}
+	if opts.MaxFields == 0 {
+		opts.MaxFields = Max_Fields
+	}
+	if opts.MaxBodySize == 0 && opts.MaxSize > 0 && opts.MaxFiles > 0 {
+		opts.MaxBodySize = opts.MaxSize*int64(opts.MaxFiles) + Max_FieldSize + Max_Overhead
+	}
+	if len(opts.Naming) == 0 {
+		opts.Naming = Naming_Hash
+	}
+	exts := make([]string, len(opts.AllowedExts))
+	for i, ext := range opts.AllowedExts {
+		exts[i] = normalizeExt(ext)
+	}
+	opts.AllowedExts = exts
+	return &myUploader{options: opts}
+}
+
+func (u *myUploader) Save(req *http.Request, dir string) (result *Result, err error) {
+	mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return nil, newError(http.StatusUnsupportedMediaType, "", "content type must be multipart/form-data")
+	}
+	if u.options.Storage == nil {
+		return nil, genError("storage not set")
+	}
+	if max := u.options.MaxBodySize; max > 0 {
+		if req.ContentLength > max {
+			return nil, newError(http.StatusRequestEntityTooLarge, "", "request body exceeds %d bytes", max)
+		}
+		req.Body = http.MaxBytesReader(nil, req.Body, max)
+	}
+	reader, err := req.MultipartReader()
+	if err != nil {
+		return nil, newError(http.StatusBadRequest, "", "%v", err)
+	}
+
+	result = &Result{Files: []*File{}}
+	fields, fieldSize := 0, 0
+	defer func() {
+		if err != nil {
+			u.remove(result.Files)
+			result = nil
+		}
+	}()
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return result, nil
+		}
+		if err != nil {
+			return result, readError("", err)
+		}
+
+		field := part.FormName()
+		if len(part.FileName()) == 0 {
+			if fields++; u.options.MaxFields > 0 && fields > u.options.MaxFields {
+				return result, newError(http.StatusRequestEntityTooLarge, field, "too many fields, at most %d", u.options.MaxFields)
+			}
+			value, err := ioutil.ReadAll(io.LimitReader(part, int64(Max_FieldSize-fieldSize)+1))
+			if err != nil {
+				return result, readError(field, err)
+			}
+			if fieldSize += len(value); fieldSize > Max_FieldSize {
+				return result, newError(http.StatusRequestEntityTooLarge, field, "fields exceed %d bytes in total", Max_FieldSize)
+			}
+			if result.Fields == nil {
+				result.Fields = make(map[string][]string)
+			}
+			result.Fields[field] = append(result.Fields[field], string(value))
+			continue
+		}
+
+		if !u.acceptField(field) {
+			return result, newError(http.StatusBadRequest, field, "field does not accept files")
+		}
+		if u.options.MaxFiles > 0 && len(result.Files) >= u.options.MaxFiles {
+			return result, newError(http.StatusRequestEntityTooLarge, field, "too many files, at most %d", u.options.MaxFiles)
+		}
+		file, err := u.saveFile(part, field, part.FileName(), dir)
+		if err != nil {
+			return result, err
+		}
+		result.Files = append(result.Files, file)
+	}
+}
+
+func (u *myUploader) SaveFile(r io.Reader, filename, dir string) (*File, error) {
+	if u.options.Storage == nil {
+		return nil, genError("storage not set")
+	}
+	return u.saveFile(r, "", filename, dir)
+}
+
+// saveFile 检查文件的扩展名、内容类型和大小，先写入临时文件，检查通过后保存到存储。
+func (u *myUploader) saveFile(src io.Reader, field, filename, dir string) (*File, error) {
+	filename = path.Base(path.Clean("/" + strings.ReplaceAll(filename, "\\", "/")))
+	ext := normalizeExt(path.Ext(filename))
+	if len(u.options.AllowedExts) > 0 && !contains(u.options.AllowedExts, ext) {
+		return nil, newError(http.StatusUnsupportedMediaType, field, "file extension %q is not allowed", ext)
+	}
+
+	// 按文件内容检测类型，不使用客户端提供的Content-Type
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, readError(field, err)
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if len(u.options.AllowedTypes) > 0 && !matchType(u.options.AllowedTypes, contentType) {
+		return nil, newError(http.StatusUnsupportedMediaType, field, "file type %s is not allowed", contentType)
+	}
+	if !u.matchExt(ext, contentType) {
+		return nil, newError(http.StatusUnsupportedMediaType, field, "file extension %q with content type %s is not allowed", ext, contentType)
+	}
+
+	tmp, err := ioutil.TempFile(u.options.TempDir, "upload-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	var r io.Reader = io.MultiReader(bytes.NewReader(head), src)
+	if u.options.MaxSize > 0 {
+		r = io.LimitReader(r, u.options.MaxSize+1)
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), r)
+	if err != nil {
+		return nil, readError(field, err)
+	}
+	if u.options.MaxSize > 0 && size > u.options.MaxSize {
+		return nil, newError(http.StatusRequestEntityTooLarge, field, "file exceeds %d bytes", u.options.MaxSize)
+	}
+	if u.options.StripMetadata && stripTypes[contentType] {
+		h.Reset()
+		if size, err = u.strip(tmp, h, field); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	file := &File{
+		Field:       field,
+		Filename:    filename,
+		Size:        size,
+		ContentType: contentType,
+	}
+	dir = cleanDir(dir)
+	if len(u.options.DateShard) > 0 {
+		dir = path.Join(dir, cleanDir(time.Now().Format(u.options.DateShard)))
+	}
+
+	if u.options.Naming == Naming_Random {
+		name, err := randomName(ext)
+		if err != nil {
+			return nil, err
+		}
+		file.Name = path.Join(dir, name)
+	} else {
+		file.Hash = hex.EncodeToString(h.Sum(nil))
+		file.Name = path.Join(dir, file.Hash+ext)
+	}
+
+	if len(file.Hash) > 0 && u.dedup(file) {
+		file.URL = u.options.Storage.URL(file.Name)
+	} else if err := u.store(file, tmp); err != nil {
+		return nil, err
+	}
+	if err := u.process(file, tmp); err != nil {
+		u.remove([]*File{file})
+		return nil, err
+	}
+	return file, nil
+}
+
+// store 保存文件，按内容命名时在索引中记录。
+func (u *myUploader) store(file *File, tmp *os.File) error {
+	if err := u.options.Storage.Put(file.Name, tmp, file.Size, file.ContentType); err != nil {
+		return err
+	}
+	file.URL = u.options.Storage.URL(file.Name)
+	if len(file.Hash) == 0 || u.options.Index == nil {
+		return nil
+	}
+	entry := &Entry{
+		Hash:        file.Hash,
+		Name:        file.Name,
+		URL:         file.URL,
+		Size:        file.Size,
+		ContentType: file.ContentType,
+		Filenames:   []string{file.Filename},
+		Created:     time.Now(),
+	}
+	if err := u.saveEntry(entry); err != nil {
+		u.options.Storage.Delete(file.Name)
+		return err
+	}
+	return nil
+}
+
+// strip 将临时文件中的图片重新编码，去除元数据，返回重新编码后的大小，内容同时写入h。
+func (u *myUploader) strip(tmp *os.File, h io.Writer, field string) (int64, error) {
+	img, format, err := u.decode(tmp, field)
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	if err := imaging.Encode(&buf, img, format, 0); err != nil {
+		return 0, err
+	}
+	if err := tmp.Truncate(0); err != nil {
+		return 0, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return io.Copy(io.MultiWriter(tmp, h), &buf)
+}
+
+// decode 从头解码图片，超过像素限制或无法解码时返回*Error。
+func (u *myUploader) decode(r io.ReadSeeker, field string) (image.Image, string, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+	img, format, err := imaging.Decode(r, u.options.MaxPixels)
+	if err == imaging.ErrTooLarge {
+		return nil, "", newError(http.StatusRequestEntityTooLarge, field, "image exceeds the pixel limit")
+	}
+	if err != nil {
+		return nil, "", newError(http.StatusUnsupportedMediaType, field, "invalid image: %v", err)
+	}
+	return img, format, nil
+}
+
+// process 按Options.Variants生成图片的变体，相同内容的文件已存在时只生成不存在的变体。
+// 出错时删除本次生成的变体，原图由调用方处理。
+func (u *myUploader) process(file *File, r io.ReadSeeker) (err error) {
+	if len(u.options.Variants) == 0 || !imageTypes[file.ContentType] {
+		return nil
+	}
+	img, format, err := u.decode(r, file.Field)
+	if err != nil {
+		return err
+	}
+
+	var written []string
+	defer func() {
+		if err != nil {
+			for _, name := range written {
+				u.options.Storage.Delete(name)
+			}
+		}
+	}()
+
+	b := img.Bounds()
+	file.Width, file.Height = b.Dx(), b.Dy()
+	file.Variants = make(map[string]*Image, len(u.options.Variants))
+	base := strings.TrimSuffix(file.Name, path.Ext(file.Name))
+	for _, v := range u.options.Variants {
+		f := v.Format
+		if len(f) == 0 {
+			f = format
+		}
+		variant := &Image{
+			Name:        base + "_" + dirSegment.ReplaceAllString(v.Name, "") + imaging.Extension(f),
+			ContentType: imaging.ContentType(f),
+		}
+		variant.Width, variant.Height = v.Size(file.Width, file.Height)
+
+		if file.Duplicate {
+			if info, err := u.options.Storage.Stat(variant.Name); err == nil {
+				variant.Size = info.Size
+				variant.URL = u.options.Storage.URL(variant.Name)
+				file.Variants[v.Name] = variant
+				continue
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := imaging.Encode(&buf, imaging.Resize(img, v), f, v.Quality); err != nil {
+			return err
+		}
+		variant.Size = int64(buf.Len())
+		if err := u.options.Storage.Put(variant.Name, &buf, variant.Size, variant.ContentType); err != nil {
+			return err
+		}
+		written = append(written, variant.Name)
+		variant.URL = u.options.Storage.URL(variant.Name)
+		file.Variants[v.Name] = variant
+	}
+	return nil
+}
+
+// dedup 查找相同内容的文件，找到时设置file为已有的文件，并在索引中记录上传的文件名。
+// 索引中的文件已被删除时按未找到处理。
+func (u *myUploader) dedup(file *File) bool {
+	if u.options.Index == nil {
+		if _, err := u.options.Storage.Stat(file.Name); err != nil {
+			return false
+		}
+		file.Duplicate = true
+		return true
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	entry, err := u.Lookup(file.Hash)
+	if err != nil {
+		return false
+	}
+	if _, err := u.options.Storage.Stat(entry.Name); err != nil {
+		return false
+	}
+	if !contains(entry.Filenames, file.Filename) {
+		entry.Filenames = append(entry.Filenames, file.Filename)
+		u.saveEntry(entry)
+	}
+	file.Name = entry.Name
+	file.Duplicate = true
+	return true
+}
+
+func (u *myUploader) Lookup(hash string) (*Entry, error) {
+	if u.options.Index == nil || !validHash.MatchString(hash) {
+		return nil, storage.ErrNotExist
+	}
+	r, err := u.options.Index.Get(indexName(hash))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	entry := &Entry{}
+	if err := json.NewDecoder(r).Decode(entry); err != nil {
+		return nil, err
+	}
+	entry.URL = u.options.Storage.URL(entry.Name)
+	return entry, nil
+}
+
+func (u *myUploader) saveEntry(entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return u.options.Index.Put(indexName(entry.Hash), bytes.NewReader(data), int64(len(data)), "application/json")
+}
+
+func (u *myUploader) Remove(result *Result) {
+	if result != nil {
+		u.remove(result.Files)
+	}
+}
+
+// remove 删除本次保存的文件，不删除已存在的相同内容的文件。
+func (u *myUploader) remove(files []*File) {
+	for _, f := range files {
+		if f.Duplicate {
+			continue
+		}
+		u.options.Storage.Delete(f.Name)
+		for _, variant := range f.Variants {
+			u.options.Storage.Delete(variant.Name)
+		}
+		if len(f.Hash) > 0 && u.options.Index != nil {
+			u.options.Index.Delete(indexName(f.Hash))
+		}
+	}
+}
+
+// readError 读取请求体出错时的错误，超过Options.MaxBodySize时为413。
+func readError(field string, err error) *Error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return newError(http.StatusRequestEntityTooLarge, field, "request body exceeds %d bytes", tooLarge.Limit)
+	}
+	return newError(http.StatusBadRequest, field, "%v", err)
+}
+
+func (u *myUploader) acceptField(field string) bool {
+	return len(u.options.Fields) == 0 || contains(u.options.Fields, field)
+}
+
+var dirSegment = regexp.MustCompile(`[^a-zA-Z0-9_\-]`)
+
+// cleanDir 清理目录，只保留字母、数字、下划线和横线，不能指向上级目录。
+func cleanDir(dir string) string {
+	segs := strings.Split(path.Clean("/"+strings.ReplaceAll(dir, "\\", "/")), "/")
+	cleaned := segs[:0]
+	for _, seg := range segs {
+		if seg = dirSegment.ReplaceAllString(seg, ""); len(seg) > 0 {
+			cleaned = append(cleaned, seg)
+		}
+	}
+	return strings.Join(cleaned, "/")
+}
+
+// randomName 随机的文件名，避免覆盖已有的文件和猜测文件名。
+func randomName(ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b) + ext, nil
+}
+
+// imageTypes 可以生成变体的图片类型。
+var imageTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+}
+
+// stripTypes 可以去除元数据的图片类型，见Options.StripMetadata。
+var stripTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+}
+
+var validHash = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// indexName 内容在索引中的文件名，按前两位分目录，避免单个目录下的文件过多。
+func indexName(hash string) string {
+	return path.Join(hash[:2], hash+Index_Extension)
+}
+
+var extChars = regexp.MustCompile(`[^a-z0-9]`)
+
+// normalizeExt 小写的扩展名，只保留字母和数字，如 .JPG 转换为 .jpg。
+func normalizeExt(ext string) string {
+	ext = extChars.ReplaceAllString(strings.ToLower(ext), "")
+	if len(ext) == 0 {
+		return ""
+	}
+	return "." + ext
+}
+
+// matchType 判断类型是否匹配，支持 image/* 的写法。
+func matchType(patterns []string, contentType string) bool {
+	for _, p := range patterns {
+		if p == contentType || (strings.HasSuffix(p, "/*") && strings.HasPrefix(contentType, p[:len(p)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// activeTypes 浏览器会执行脚本的类型，扩展名或内容为这些类型时需要明确允许，且两者必须一致。
+var activeTypes = map[string]bool{
+	"text/html":                true,
+	"application/xhtml+xml":    true,
+	"image/svg+xml":            true,
+	"text/xml":                 true,
+	"application/xml":          true,
+	"text/javascript":          true,
+	"application/javascript":   true,
+	"application/x-javascript": true,
+}
+
+// genericTypes 无法从内容进一步区分的类型，如 .csv 检测为text/plain，.docx 检测为application/zip。
+var genericTypes = map[string]bool{
+	"text/plain":               true,
+	"application/octet-stream": true,
+	"application/zip":          true,
+	"application/x-gzip":       true,
+}
+
+// matchExt 判断扩展名与检测的内容类型是否相符，存储按扩展名输出Content-Type，
+// 不相符时文件会以扩展名的类型输出：
+// 任一方为activeTypes时，两者必须相同，且内容类型在AllowedTypes或扩展名在AllowedExts中明确列出；
+// 否则扩展名的类型与内容类型相同时相符；未知的扩展名由存储按内容检测类型，视为相符；
+// 内容为genericTypes或与扩展名的类型属于同一大类（如 image/*）时相符。
+func (u *myUploader) matchExt(ext, contentType string) bool {
+	t, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext))
+	if activeTypes[t] || activeTypes[contentType] {
+		return t == contentType && (contains(u.options.AllowedTypes, contentType) || contains(u.options.AllowedExts, ext))
+	}
+	switch {
+	case t == contentType, len(t) == 0, genericTypes[contentType]:
+		return true
+	}
+	return topLevelType(t) == topLevelType(contentType)
+}
+
+func topLevelType(mediaType string) string {
+	if i := strings.IndexByte(mediaType, '/'); i >= 0 {
+		return mediaType[:i]
+	}
+	return mediaType
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
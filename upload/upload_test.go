@@ -0,0 +1,231 @@
+package upload
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"io"
+	"io/ioutil"
+	"letgo/imaging"
+	"letgo/storage"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR")
+
+type testPart struct {
+	field    string
+	filename string // 为空时为非文件字段
+	data     []byte
+}
+
+func newUploadRequest(t *testing.T, parts ...testPart) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, p := range parts {
+		var err error
+		if len(p.filename) == 0 {
+			err = w.WriteField(p.field, string(p.data))
+		} else {
+			var fw io.Writer
+			if fw, err = w.CreateFormFile(p.field, p.filename); err == nil {
+				_, err = fw.Write(p.data)
+			}
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	return req
+}
+
+func statusOf(err error) int {
+	if e, ok := err.(*Error); ok {
+		return e.StatusCode()
+	}
+	return 0
+}
+
+func TestMatchExt(t *testing.T) {
+	strict := New(Options{}).(*myUploader)
+	html := New(Options{AllowedTypes: []string{"text/html", "image/*"}}).(*myUploader)
+	wildcard := New(Options{AllowedTypes: []string{"text/*"}}).(*myUploader)
+	tests := []struct {
+		u           *myUploader
+		ext         string
+		contentType string
+		match       bool
+	}{
+		{strict, ".png", "image/png", true},
+		{strict, ".jpg", "image/png", true},
+		{strict, ".json", "text/plain", true},
+		{strict, ".css", "text/plain", true},
+		{strict, ".unknownext", "application/octet-stream", true},
+		{strict, "", "image/png", true},
+		// 浏览器会执行的类型需要明确允许
+		{strict, ".html", "text/html", false},
+		{strict, ".html", "image/png", false},
+		{strict, ".svg", "text/plain", false},
+		{strict, ".svg", "text/xml", false},
+		{strict, ".js", "text/plain", false},
+		{strict, ".png", "text/html", false},
+		{strict, "", "text/html", false},
+		{strict, ".pdf", "image/png", false},
+		{html, ".html", "text/html", true},
+		{html, ".html", "image/png", false},
+		{html, "", "text/html", false},
+		{wildcard, ".html", "text/html", false},
+	}
+	for _, tt := range tests {
+		if got := tt.u.matchExt(tt.ext, tt.contentType); got != tt.match {
+			t.Errorf("matchExt(%q, %s) with %v = %v, want %v", tt.ext, tt.contentType, tt.u.options.AllowedTypes, got, tt.match)
+		}
+	}
+}
+
+func TestSaveRejectsHTML(t *testing.T) {
+	page := testPart{"file", "x.html", []byte("<html><script>alert(1)</script></html>")}
+	_, err := New(Options{Storage: storage.NewMemory("/files")}).Save(newUploadRequest(t, page), "")
+	if statusOf(err) != http.StatusUnsupportedMediaType {
+		t.Errorf("Save x.html by default: %v, want status %d", err, http.StatusUnsupportedMediaType)
+	}
+
+	u := New(Options{Storage: storage.NewMemory("/files"), AllowedExts: []string{".html"}})
+	if _, err := u.Save(newUploadRequest(t, page), ""); err != nil {
+		t.Errorf("Save x.html with .html allowed: %v", err)
+	}
+}
+
+func TestSaveRejectsMismatchedExt(t *testing.T) {
+	u := New(Options{Storage: storage.NewMemory("/files"), Naming: Naming_Random})
+	_, err := u.Save(newUploadRequest(t, testPart{"file", "evil.html", pngHeader}), "")
+	if statusOf(err) != http.StatusUnsupportedMediaType {
+		t.Fatalf("Save evil.html: %v, want status %d", err, http.StatusUnsupportedMediaType)
+	}
+
+	result, err := u.Save(newUploadRequest(t, testPart{"file", "ok.png", pngHeader}), "img")
+	if err != nil {
+		t.Fatalf("Save ok.png: %v", err)
+	}
+	if f := result.Files[0]; f.ContentType != "image/png" || len(f.Name) == 0 {
+		t.Errorf("Save ok.png: %+v", f)
+	}
+}
+
+func TestSaveLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   Options
+		parts  []testPart
+		status int
+	}{
+		{"max size", Options{MaxSize: 8}, []testPart{{"file", "a.png", pngHeader}}, http.StatusRequestEntityTooLarge},
+		{"max files", Options{MaxFiles: 1}, []testPart{{"a", "a.png", pngHeader}, {"b", "b.png", pngHeader}}, http.StatusRequestEntityTooLarge},
+		{"max fields", Options{MaxFields: 2}, []testPart{{"a", "", nil}, {"b", "", nil}, {"c", "", nil}}, http.StatusRequestEntityTooLarge},
+		{"field size", Options{}, []testPart{{"a", "", make([]byte, Max_FieldSize/2+1)}, {"b", "", make([]byte, Max_FieldSize/2)}}, http.StatusRequestEntityTooLarge},
+		{"body size", Options{MaxBodySize: 64}, []testPart{{"file", "a.png", pngHeader}}, http.StatusRequestEntityTooLarge},
+		{"fields", Options{}, []testPart{{"a", "", []byte("1")}, {"file", "a.png", pngHeader}}, 0},
+	}
+	for _, tt := range tests {
+		tt.opts.Storage = storage.NewMemory("")
+		_, err := New(tt.opts).Save(newUploadRequest(t, tt.parts...), "")
+		if statusOf(err) != tt.status {
+			t.Errorf("%s: Save: %v, want status %d", tt.name, err, tt.status)
+		}
+	}
+}
+
+func TestSaveUnknownLength(t *testing.T) {
+	// 请求体长度未知时由MaxBytesReader限制
+	req := newUploadRequest(t, testPart{"file", "a.png", append(pngHeader, make([]byte, 1024)...)})
+	req.ContentLength = -1
+	_, err := New(Options{Storage: storage.NewMemory(""), MaxBodySize: 512}).Save(req, "")
+	if statusOf(err) != http.StatusRequestEntityTooLarge {
+		t.Errorf("Save: %v, want status %d", err, http.StatusRequestEntityTooLarge)
+	}
+}
+
+// newJPEG 带EXIF段的JPEG图片。
+func newJPEG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 16, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	exif := []byte("Exif\x00\x00II\x2a\x00\x08\x00\x00\x00\x00\x00")
+	app1 := append([]byte{0xFF, 0xE1, 0, byte(len(exif) + 2)}, exif...)
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+func TestSaveStripMetadata(t *testing.T) {
+	store := storage.NewMemory("")
+	for _, strip := range []bool{false, true} {
+		u := New(Options{Storage: store, StripMetadata: strip})
+		result, err := u.Save(newUploadRequest(t, testPart{"file", "photo.jpg", newJPEG(t)}), "")
+		if err != nil {
+			t.Fatalf("strip %v: Save: %v", strip, err)
+		}
+		r, err := store.Get(result.Files[0].Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := ioutil.ReadAll(r)
+		r.Close()
+		if hasExif := bytes.Contains(data, []byte("Exif")); hasExif == strip {
+			t.Errorf("strip %v: stored file contains EXIF %v", strip, hasExif)
+		}
+		if int64(len(data)) != result.Files[0].Size {
+			t.Errorf("strip %v: size %d, stored %d bytes", strip, result.Files[0].Size, len(data))
+		}
+	}
+}
+
+// failingStorage 保存指定文件时出错。
+type failingStorage struct {
+	storage.Storage
+	fail string
+}
+
+func (s *failingStorage) Put(name string, r io.Reader, size int64, contentType string) error {
+	if name == s.fail {
+		return errors.New("put failed")
+	}
+	return s.Storage.Put(name, r, size, contentType)
+}
+
+func TestProcessCleanupForDuplicate(t *testing.T) {
+	store := storage.NewMemory("")
+	data := newJPEG(t)
+	result, err := New(Options{Storage: store}).Save(newUploadRequest(t, testPart{"file", "a.jpg", data}), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := result.Files[0].Name
+	base := name[:len(name)-len(".jpg")]
+
+	// 相同内容再次上传，生成变体时第二个变体保存失败
+	u := New(Options{
+		Storage:  &failingStorage{Storage: store, fail: base + "_medium.jpg"},
+		Variants: []imaging.Variant{{Name: "thumb", Width: 4}, {Name: "medium", Width: 8}},
+	})
+	if _, err := u.Save(newUploadRequest(t, testPart{"file", "a.jpg", data}), ""); err == nil {
+		t.Fatal("Save: want error")
+	}
+	if _, err := store.Stat(base + "_thumb.jpg"); !errors.Is(err, storage.ErrNotExist) {
+		t.Errorf("thumb variant: %v, want removed", err)
+	}
+	if _, err := store.Stat(name); err != nil {
+		t.Errorf("existing file: %v, want kept", err)
+	}
+}
@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var errorPrefix = "validation error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
+
+// FieldError 字段校验失败的信息。
+type FieldError struct {
+	Field   string `json:"field"`           // 字段路径，优先使用json tag，如 items[0].name
+	Rule    string `json:"rule"`            // 未通过的规则
+	Param   string `json:"param,omitempty"` // 规则的参数
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// Errors 全部字段的校验错误。
+type Errors []*FieldError
+
+func (es Errors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Sprintf("%s: %s", errorPrefix, strings.Join(msgs, "; "))
+}
@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag_Validate 校验规则的tag，多个规则用逗号分隔，如 `validate:"required,min=3,max=64"`。
+const Tag_Validate = "validate"
+
+// ValidatorFunc 校验函数，field为字段的值（指针已解引用），param为规则的参数。
+type ValidatorFunc func(field reflect.Value, param string) bool
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Validate 按字段的validate tag校验结构体，嵌套的结构体及结构体切片会递归校验。
+// 校验失败时返回Errors，包含全部未通过的字段。
+func Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var errs Errors
+	validateStruct(rv, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, prefix string, errs *Errors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 && !sf.Anonymous {
+			continue
+		}
+		fv := v.Field(i)
+
+		name := prefix
+		if !sf.Anonymous {
+			name = joinField(prefix, fieldName(sf))
+		}
+
+		if tag := sf.Tag.Get(Tag_Validate); len(tag) > 0 && tag != "-" {
+			if !validateField(fv, name, tag, errs) {
+				continue
+			}
+		}
+		dive(fv, name, errs)
+	}
+}
+
+// validateField 按规则校验字段，返回false时不再校验嵌套的字段。
+// 字段为零值时：有required时只报告required；有omitempty时跳过其它规则；
+// 否则按零值校验全部规则，如 min=18 不接受0。nil指针表示未提供，只校验required。
+func validateField(fv reflect.Value, name, tag string, errs *Errors) bool {
+	rules := strings.Split(tag, ",")
+	for i := range rules {
+		rules[i] = strings.TrimSpace(rules[i])
+	}
+
+	if isZero(fv) {
+		for _, rule := range rules {
+			if rule == "required" {
+				*errs = append(*errs, newFieldError(name, rule, ""))
+				return false
+			}
+		}
+		if hasRule(rules, "omitempty") || fv.Kind() == reflect.Ptr || fv.Kind() == reflect.Interface {
+			return false
+		}
+	}
+
+	for fv.Kind() == reflect.Ptr {
+		fv = fv.Elem()
+	}
+	for _, rule := range rules {
+		if rule == "required" || rule == "omitempty" || len(rule) == 0 {
+			continue
+		}
+		ruleName, param := splitRule(rule)
+		fn, ok := lookupValidator(ruleName)
+		if !ok {
+			panic(genError(fmt.Sprintf("unknown rule %s on field %s", ruleName, name)))
+		}
+		if !fn(fv, param) {
+			*errs = append(*errs, newFieldError(name, ruleName, param))
+		}
+	}
+	return true
+}
+
+// CheckTags 检查结构体及嵌套的结构体中validate tag的规则是否都已注册，
+// 用于在注册路由时发现拼写错误，而不是在处理请求时panic。
+func CheckTags(t reflect.Type) error {
+	return checkTags(t, make(map[reflect.Type]bool))
+}
+
+func checkTags(t reflect.Type, visited map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType || visited[t] {
+		return nil
+	}
+	visited[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if len(sf.PkgPath) > 0 && !sf.Anonymous {
+			continue
+		}
+		if tag := sf.Tag.Get(Tag_Validate); len(tag) > 0 && tag != "-" {
+			for _, rule := range strings.Split(tag, ",") {
+				rule = strings.TrimSpace(rule)
+				if rule == "required" || rule == "omitempty" || len(rule) == 0 {
+					continue
+				}
+				ruleName, _ := splitRule(rule)
+				if _, ok := lookupValidator(ruleName); !ok {
+					return genError(fmt.Sprintf("unknown rule %s on field %s.%s", ruleName, t, sf.Name))
+				}
+			}
+		}
+		if err := checkTags(sf.Type, visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitRule 拆分规则名和参数，如 min=3 拆分为 min 和 3。
+func splitRule(rule string) (name, param string) {
+	if i := strings.IndexByte(rule, '='); i >= 0 {
+		return rule[:i], rule[i+1:]
+	}
+	return rule, ""
+}
+
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// dive 递归校验嵌套的结构体和结构体切片。
+func dive(fv reflect.Value, name string, errs *Errors) {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() != timeType {
+			validateStruct(fv, name, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			dive(fv.Index(i), name+"["+strconv.Itoa(i)+"]", errs)
+		}
+	}
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String, reflect.Array:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// fieldName 字段在错误信息中的名称，优先使用json tag。
+func fieldName(sf reflect.StructField) string {
+	if tag := strings.Split(sf.Tag.Get("json"), ",")[0]; len(tag) > 0 && tag != "-" {
+		return tag
+	}
+	return sf.Name
+}
+
+func joinField(prefix, name string) string {
+	if len(prefix) == 0 {
+		return name
+	}
+	return prefix + "." + name
+}
@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateZeroValues(t *testing.T) {
+	type input struct {
+		Age   int     `validate:"min=18"`
+		Sort  string  `validate:"oneof=asc desc"`
+		Email string  `validate:"omitempty,email"`
+		Name  string  `validate:"required,min=2"`
+		Nick  *string `validate:"min=2"`
+	}
+	var errs Errors
+	validateStruct(reflect.ValueOf(input{}), "", &errs)
+
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Field+":"+e.Rule)
+	}
+	want := []string{"Age:min", "Sort:oneof", "Name:required"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("errors %v, want %v", got, want)
+	}
+}
+
+func TestCheckTags(t *testing.T) {
+	type ok struct {
+		A string `validate:"omitempty, email"`
+		B []struct {
+			C int `validate:"required,min=1"`
+		}
+	}
+	type bad struct {
+		B []struct {
+			C int `validate:"mni=1"`
+		}
+	}
+	if err := CheckTags(reflect.TypeOf(ok{})); err != nil {
+		t.Errorf("CheckTags(ok): %v", err)
+	}
+	if err := CheckTags(reflect.TypeOf(bad{})); err == nil {
+		t.Error("CheckTags(bad): want error for unknown rule")
+	}
+}
@@ -0,0 +1,143 @@
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	validators = map[string]ValidatorFunc{
+		"min":     validateMin,
+		"max":     validateMax,
+		"len":     validateLen,
+		"email":   validateEmail,
+		"url":     validateURL,
+		"oneof":   validateOneOf,
+		"alpha":   validateRegexp(`^[a-zA-Z]+$`),
+		"numeric": validateRegexp(`^[-+]?[0-9]+(\.[0-9]+)?$`),
+	}
+	messages = map[string]string{
+		"required": "is required",
+		"min":      "must be at least %s",
+		"max":      "must be at most %s",
+		"len":      "must have length %s",
+		"email":    "must be a valid email address",
+		"url":      "must be a valid url",
+		"oneof":    "must be one of [%s]",
+		"alpha":    "must contain only letters",
+		"numeric":  "must be numeric",
+	}
+	mu sync.RWMutex
+)
+
+// Register 注册自定义的校验规则，message为校验失败时的说明，可以用%s引用规则的参数。
+func Register(name string, fn ValidatorFunc, message string) {
+	if fn == nil {
+		panic(genError("register validator is nil"))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := validators[name]; ok || name == "required" || name == "omitempty" {
+		panic(genError(fmt.Sprintf("%s has registed", name)))
+	}
+	validators[name] = fn
+	messages[name] = message
+}
+
+func lookupValidator(name string) (ValidatorFunc, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	fn, ok := validators[name]
+	return fn, ok
+}
+
+func newFieldError(field, rule, param string) *FieldError {
+	mu.RLock()
+	msg, ok := messages[rule]
+	mu.RUnlock()
+	if !ok || len(msg) == 0 {
+		msg = "failed on rule " + rule
+	}
+	if strings.Contains(msg, "%s") {
+		msg = fmt.Sprintf(msg, param)
+	}
+	return &FieldError{Field: field, Rule: rule, Param: param, Message: msg}
+}
+
+// size 字段用于min、max、len比较的值：数字为数值，字符串为字符数，切片等为长度。
+func size(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
+
+func compare(v reflect.Value, param string, ok func(s, p float64) bool) bool {
+	p, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	s, valid := size(v)
+	return valid && ok(s, p)
+}
+
+func validateMin(v reflect.Value, param string) bool {
+	return compare(v, param, func(s, p float64) bool { return s >= p })
+}
+
+func validateMax(v reflect.Value, param string) bool {
+	return compare(v, param, func(s, p float64) bool { return s <= p })
+}
+
+func validateLen(v reflect.Value, param string) bool {
+	return compare(v, param, func(s, p float64) bool { return s == p })
+}
+
+var emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
+func validateEmail(v reflect.Value, param string) bool {
+	return v.Kind() == reflect.String && emailRegexp.MatchString(v.String())
+}
+
+func validateURL(v reflect.Value, param string) bool {
+	if v.Kind() != reflect.String {
+		return false
+	}
+	u, err := url.Parse(v.String())
+	return err == nil && len(u.Scheme) > 0 && len(u.Host) > 0
+}
+
+// validateOneOf 值为参数中空格分隔的选项之一，如 oneof=a b c。
+func validateOneOf(v reflect.Value, param string) bool {
+	val := fmt.Sprint(v.Interface())
+	for _, option := range strings.Fields(param) {
+		if val == option {
+			return true
+		}
+	}
+	return false
+}
+
+func validateRegexp(expr string) ValidatorFunc {
+	re := regexp.MustCompile(expr)
+	return func(v reflect.Value, param string) bool {
+		return v.Kind() == reflect.String && re.MatchString(v.String())
+	}
+}
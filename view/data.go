@@ -0,0 +1,13 @@
+package view
+
+const (
+	// Folder_Layouts 布局模板的目录，位于模板根目录下。
+	Folder_Layouts = "layouts"
+	// Folder_Partials 公共模板片段的目录，位于模板根目录下，所有页面都可以引用。
+	Folder_Partials = "partials"
+
+	// Template_Content 布局中引用页面内容的模板名，如 {{template "content" .}}。
+	Template_Content = "content"
+
+	Extension = ".html"
+)
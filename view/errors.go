@@ -0,0 +1,12 @@
+package view
+
+import (
+	"errors"
+	"fmt"
+)
+
+var errorPrefix = "view error"
+
+func genError(msg string) error {
+	return errors.New(fmt.Sprintf("%s: %s", errorPrefix, msg))
+}
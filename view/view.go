@@ -0,0 +1,243 @@
+package view
+
+import (
+	"bytes"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template/parse"
+	"time"
+)
+
+// View 模板渲染。模板名为模板文件相对于根目录的路径，不含扩展名，如 user/profile。
+//
+// 页面模板可以引用Folder_Partials目录下的模板片段，如 {{template "header" .}}，
+// 片段的模板名为相对于Folder_Partials目录的路径。使用布局时执行布局模板，
+// 布局通过 {{template "content" .}} 引用页面，页面中没有定义content时整个页面作为content。
+type View interface {
+	// Render 使用默认布局渲染模板，渲染完成后才写入w，出错时w不会被写入。
+	Render(w io.Writer, name string, data interface{}) error
+	// RenderLayout 使用指定的布局渲染模板，layout为相对于Folder_Layouts目录的路径，为空时不使用布局。
+	RenderLayout(w io.Writer, layout, name string, data interface{}) error
+	// Funcs 添加模板函数，已解析的模板会重新解析。
+	Funcs(funcs template.FuncMap)
+}
+
+// Options 模板配置。
+type Options struct {
+	Root      string           // 模板根目录
+	Extension string           // 模板文件的扩展名，为空时使用Extension
+	Layout    string           // 默认布局，为空时不使用布局
+	DevMode   bool             // 开发模式，模板文件修改后自动重新解析
+	Funcs     template.FuncMap // 模板函数
+}
+
+type myView struct {
+	options Options
+
+	mu    sync.RWMutex
+	funcs template.FuncMap
+	cache map[string]*cached // key为 布局|页面
+}
+
+// cached 已解析的模板及其文件，开发模式下用于检查文件是否修改。
+type cached struct {
+	tmpl    *template.Template
+	entry   string // 执行的模板名
+	files   []string
+	modTime time.Time
+}
+
+// New 创建模板渲染，模板在第一次渲染时解析并缓存。
+func New(opts Options) View {
+	if len(opts.Extension) == 0 {
+		opts.Extension = Extension
+	}
+	v := &myView{
+		options: opts,
+		funcs:   make(template.FuncMap),
+		cache:   make(map[string]*cached),
+	}
+	for name, fn := range opts.Funcs {
+		v.funcs[name] = fn
+	}
+	return v
+}
+
+func (v *myView) Funcs(funcs template.FuncMap) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for name, fn := range funcs {
+		v.funcs[name] = fn
+	}
+	v.cache = make(map[string]*cached)
+}
+
+func (v *myView) Render(w io.Writer, name string, data interface{}) error {
+	return v.RenderLayout(w, v.options.Layout, name, data)
+}
+
+func (v *myView) RenderLayout(w io.Writer, layout, name string, data interface{}) error {
+	c, err := v.lookup(layout, name)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := c.tmpl.ExecuteTemplate(&buf, c.entry, data); err != nil {
+		return err
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// lookup 查找已解析的模板，未解析或开发模式下文件已修改时重新解析。
+func (v *myView) lookup(layout, name string) (*cached, error) {
+	key := layout + "|" + name
+
+	v.mu.RLock()
+	c, ok := v.cache[key]
+	v.mu.RUnlock()
+
+	if ok && !v.options.DevMode {
+		return c, nil
+	}
+
+	files, err := v.files(layout, name)
+	if err != nil {
+		return nil, err
+	}
+	if ok && !changed(c, files) {
+		return c, nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	c, err = v.parse(layout, name, files)
+	if err != nil {
+		return nil, err
+	}
+	v.cache[key] = c
+	return c, nil
+}
+
+// files 模板使用的文件：片段、布局、页面，页面在最后。
+func (v *myView) files(layout, name string) ([]string, error) {
+	var files []string
+	partials := filepath.Join(v.options.Root, Folder_Partials)
+	err := filepath.Walk(partials, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(file, v.options.Extension) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if len(layout) > 0 {
+		files = append(files, v.file(path.Join(Folder_Layouts, cleanName(layout))))
+	}
+	return append(files, v.file(cleanName(name))), nil
+}
+
+func (v *myView) file(name string) string {
+	return filepath.Join(v.options.Root, filepath.FromSlash(name)+v.options.Extension)
+}
+
+// parse 解析模板，调用时已持有写锁。
+func (v *myView) parse(layout, name string, files []string) (*cached, error) {
+	c := &cached{files: files}
+	var t *template.Template
+	partials := filepath.Join(v.options.Root, Folder_Partials)
+
+	for i, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if info.ModTime().After(c.modTime) {
+			c.modTime = info.ModTime()
+		}
+		text, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var tmplName string
+		switch {
+		case i == len(files)-1:
+			tmplName = cleanName(name)
+		case len(layout) > 0 && i == len(files)-2:
+			tmplName = path.Join(Folder_Layouts, cleanName(layout))
+		default:
+			rel, _ := filepath.Rel(partials, file)
+			tmplName = strings.TrimSuffix(filepath.ToSlash(rel), v.options.Extension)
+		}
+
+		// 第一个文件作为模板集合的根模板
+		var tmpl *template.Template
+		if t == nil {
+			t = template.New(tmplName).Funcs(v.funcs)
+			tmpl = t
+		} else {
+			tmpl = t.New(tmplName)
+		}
+
+		// 页面没有定义content时，使用整个页面作为布局中的content
+		isPage := i == len(files)-1
+		var before *parse.Tree
+		if content := t.Lookup(Template_Content); isPage && content != nil {
+			before = content.Tree
+		}
+		if _, err := tmpl.Parse(string(text)); err != nil {
+			return nil, err
+		}
+		if isPage && len(layout) > 0 {
+			if content := t.Lookup(Template_Content); content == nil || content.Tree == before {
+				if _, err := t.AddParseTree(Template_Content, tmpl.Tree); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	c.tmpl = t
+	c.entry = cleanName(name)
+	if len(layout) > 0 {
+		c.entry = path.Join(Folder_Layouts, cleanName(layout))
+	}
+	return c, nil
+}
+
+// changed 模板文件是否有增删或修改。
+func changed(c *cached, files []string) bool {
+	if len(files) != len(c.files) {
+		return true
+	}
+	for i, file := range files {
+		if file != c.files[i] {
+			return true
+		}
+		info, err := os.Stat(file)
+		if err != nil || info.ModTime().After(c.modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanName 模板名不能指向根目录以外的文件。
+func cleanName(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
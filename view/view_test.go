@@ -0,0 +1,163 @@
+package view
+
+import (
+	"bytes"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTemplates 在临时目录中写入模板文件，key为相对路径。
+func writeTemplates(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, text := range files {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(file, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// touch 将文件的修改时间设置到之后，避免文件系统时间精度导致修改未被发现。
+func touch(t *testing.T, file string, after time.Duration) {
+	t.Helper()
+	mod := time.Now().Add(after)
+	if err := os.Chtimes(file, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func render(t *testing.T, v View, layout, name string, data interface{}) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := v.RenderLayout(&buf, layout, name, data); err != nil {
+		t.Fatalf("RenderLayout(%q, %q): %v", layout, name, err)
+	}
+	return buf.String()
+}
+
+func TestRenderLayout(t *testing.T) {
+	root := t.TempDir()
+	writeTemplates(t, root, map[string]string{
+		"layouts/main.html":         `<main>{{template "content" .}}</main>`,
+		"layouts/admin/main.html":   `<admin>{{template "header" .}}{{template "content" .}}</admin>`,
+		"layouts/block.html":        `<block>{{block "content" .}}default{{end}}</block>`,
+		"partials/header.html":      `<h1>{{.}}</h1>`,
+		"partials/forms/input.html": `<input value="{{.}}">`,
+		"home.html":                 `home {{.}}`,
+		"user/profile.html":         `{{define "content"}}profile {{.}}{{end}}ignored`,
+		"form.html":                 `{{template "forms/input" .}}`,
+	})
+	v := New(Options{Root: root, Layout: "main"})
+
+	tests := []struct {
+		layout string
+		name   string
+		want   string
+	}{
+		// 页面没有定义content时整个页面作为content
+		{"main", "home", "<main>home &lt;x&gt;</main>"},
+		{"main", "user/profile", "<main>profile &lt;x&gt;</main>"},
+		{"admin/main", "home", "<admin><h1>&lt;x&gt;</h1>home &lt;x&gt;</admin>"},
+		// 布局中block定义的默认content被页面替换
+		{"block", "home", "<block>home &lt;x&gt;</block>"},
+		{"block", "user/profile", "<block>profile &lt;x&gt;</block>"},
+		{"", "home", "home &lt;x&gt;"},
+		{"", "form", `<input value="&lt;x&gt;">`},
+		// 模板名不能指向根目录以外
+		{"", "../../home", "home &lt;x&gt;"},
+	}
+	for _, tt := range tests {
+		if got := render(t, v, tt.layout, tt.name, "<x>"); got != tt.want {
+			t.Errorf("RenderLayout(%q, %q) = %s, want %s", tt.layout, tt.name, got, tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := v.Render(&buf, "home", "d"); err != nil || buf.String() != "<main>home d</main>" {
+		t.Errorf("Render with default layout = %q, %v", buf.String(), err)
+	}
+	if err := v.Render(&buf, "missing", nil); err == nil {
+		t.Error("Render missing template: want error")
+	}
+}
+
+func TestRenderErrorWritesNothing(t *testing.T) {
+	root := t.TempDir()
+	writeTemplates(t, root, map[string]string{
+		"page.html": `before {{.Missing.Field}}`,
+	})
+	var buf bytes.Buffer
+	err := New(Options{Root: root}).Render(&buf, "page", map[string]interface{}{"Missing": 1})
+	if err == nil || buf.Len() > 0 {
+		t.Errorf("Render = %q, %v, want error and nothing written", buf.String(), err)
+	}
+}
+
+func TestCacheAndDevMode(t *testing.T) {
+	for _, devMode := range []bool{false, true} {
+		root := t.TempDir()
+		writeTemplates(t, root, map[string]string{
+			"layouts/main.html": `[{{template "content" .}}]`,
+			"page.html":         `v1`,
+		})
+		v := New(Options{Root: root, Layout: "main", DevMode: devMode})
+		if got := render(t, v, "main", "page", nil); got != "[v1]" {
+			t.Fatalf("dev %v: first render %s", devMode, got)
+		}
+
+		// 修改页面
+		writeTemplates(t, root, map[string]string{"page.html": `v2`})
+		touch(t, filepath.Join(root, "page.html"), time.Second)
+		want := "[v1]"
+		if devMode {
+			want = "[v2]"
+		}
+		if got := render(t, v, "main", "page", nil); got != want {
+			t.Errorf("dev %v: after page change %s, want %s", devMode, got, want)
+		}
+
+		// 修改布局
+		writeTemplates(t, root, map[string]string{"layouts/main.html": `({{template "content" .}})`})
+		touch(t, filepath.Join(root, "layouts", "main.html"), 2*time.Second)
+		if devMode {
+			want = "(v2)"
+		}
+		if got := render(t, v, "main", "page", nil); got != want {
+			t.Errorf("dev %v: after layout change %s, want %s", devMode, got, want)
+		}
+
+		// 新增片段
+		writeTemplates(t, root, map[string]string{
+			"partials/tag.html": `#tag`,
+			"tagged.html":       `{{template "tag"}}`,
+		})
+		if got := render(t, v, "", "tagged", nil); got != "#tag" {
+			t.Errorf("dev %v: new page using new partial %s", devMode, got)
+		}
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	root := t.TempDir()
+	writeTemplates(t, root, map[string]string{
+		"page.html": `{{greet .}}`,
+	})
+	v := New(Options{Root: root, Funcs: template.FuncMap{"greet": func(s string) string { return "hi " + s }}})
+	if got := render(t, v, "", "page", "a"); got != "hi a" {
+		t.Errorf("render = %s", got)
+	}
+
+	// 替换函数后重新解析已缓存的模板
+	v.Funcs(template.FuncMap{"greet": strings.ToUpper})
+	if got := render(t, v, "", "page", "a"); got != "A" {
+		t.Errorf("render after Funcs = %s, want A", got)
+	}
+}